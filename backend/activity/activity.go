@@ -0,0 +1,40 @@
+// Package activity keeps a log of changes made to many tasks at once, so they
+// can be traced back to who made them.
+package activity
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Record adds an entry to the log. Failures are only logged: the change itself
+// has already been made.
+func Record(entry models.Activity) {
+	entry.ID = primitive.NewObjectID()
+	entry.CreatedAt = time.Now()
+	if _, err := database.GetCollection("activity").InsertOne(context.Background(), entry); err != nil {
+		log.Printf("Could not record %s activity: %v", entry.Action, err)
+	}
+}
+
+// List returns the latest entries matching filter, newest first
+func List(ctx context.Context, filter bson.M, limit int64) ([]models.Activity, error) {
+	opts := options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(limit)
+	cursor, err := database.GetCollection("activity").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch activity: %v", err)
+	}
+	entries := []models.Activity{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse activity: %v", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,381 @@
+package aiconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Harm categories and thresholds accepted in AIModelSettings.Safety
+var (
+	SafetyCategories = []string{"harassment", "hate_speech", "sexually_explicit", "dangerous_content"}
+	SafetyThresholds = []string{"block_none", "block_only_high", "block_medium_and_above", "block_low_and_above"}
+)
+
+var (
+	baseMutex sync.RWMutex
+	base      = defaults
+)
+
+// Load builds the base config: the built-in defaults, then the JSON file named
+// by AI_CONFIG_FILE, then the AI_MODEL, AI_TEMPERATURE, per-use
+// AI_MODEL_<USE> / AI_TEMPERATURE_<USE> and AI_QUOTA_* environment variables. Versions saved
+// through the API are applied on top of it at call time.
+func Load() error {
+	config := defaults
+	if path := os.Getenv("AI_CONFIG_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read AI config file: %v", err)
+		}
+		var file models.AIConfig
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("could not parse AI config file: %v", err)
+		}
+		if err := Validate(file); err != nil {
+			return fmt.Errorf("invalid AI config file: %v", err)
+		}
+		config = Merge(config, file)
+	}
+
+	env, err := fromEnv()
+	if err != nil {
+		return err
+	}
+	config = Merge(config, env)
+
+	baseMutex.Lock()
+	base = config
+	baseMutex.Unlock()
+	return nil
+}
+
+func fromEnv() (models.AIConfig, error) {
+	config := models.AIConfig{Models: map[string]models.AIModelSettings{}}
+	for _, use := range append([]string{UseDefault}, uses()...) {
+		suffix := ""
+		if use != UseDefault {
+			suffix = "_" + strings.ToUpper(use)
+		}
+		settings := models.AIModelSettings{Name: os.Getenv("AI_MODEL" + suffix)}
+		if value := os.Getenv("AI_TEMPERATURE" + suffix); value != "" {
+			temperature, err := strconv.ParseFloat(value, 32)
+			if err != nil {
+				return models.AIConfig{}, fmt.Errorf("invalid AI_TEMPERATURE%s: %v", suffix, err)
+			}
+			t := float32(temperature)
+			settings.Temperature = &t
+		}
+		if settings.Name != "" || settings.Temperature != nil {
+			config.Models[use] = settings
+		}
+	}
+	for name, quota := range map[string]**int64{
+		"AI_QUOTA_USER_DAILY":        &config.Quotas.UserDaily,
+		"AI_QUOTA_USER_MONTHLY":      &config.Quotas.UserMonthly,
+		"AI_QUOTA_WORKSPACE_DAILY":   &config.Quotas.WorkspaceDaily,
+		"AI_QUOTA_WORKSPACE_MONTHLY": &config.Quotas.WorkspaceMonthly,
+	} {
+		if value := os.Getenv(name); value != "" {
+			tokens, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return models.AIConfig{}, fmt.Errorf("invalid %s: %v", name, err)
+			}
+			*quota = &tokens
+		}
+	}
+	return config, Validate(config)
+}
+
+func uses() []string {
+	return []string{UseChat, UseSuggestion, UseStandup, UseBreakdown, UseQuery, UseQuickAdd, UseTranscribe, UseExtract}
+}
+
+// Base is the config from defaults, file and environment
+func Base() models.AIConfig {
+	baseMutex.RLock()
+	defer baseMutex.RUnlock()
+	return base
+}
+
+// Merge returns base with every entry set in override replacing it. Model
+// settings are merged field by field.
+func Merge(base, override models.AIConfig) models.AIConfig {
+	merged := models.AIConfig{
+		Models:  map[string]models.AIModelSettings{},
+		Prompts: map[string]string{},
+		Tools:   map[string]string{},
+		Costs:   map[string]models.AIModelCost{},
+		Quotas:  base.Quotas,
+	}
+	for use, settings := range base.Models {
+		merged.Models[use] = settings
+	}
+	for use, settings := range override.Models {
+		merged.Models[use] = mergeSettings(merged.Models[use], settings)
+	}
+	for model, cost := range base.Costs {
+		merged.Costs[model] = cost
+	}
+	for model, cost := range override.Costs {
+		merged.Costs[model] = cost
+	}
+	for _, q := range []struct{ into, override **int64 }{
+		{&merged.Quotas.UserDaily, &override.Quotas.UserDaily},
+		{&merged.Quotas.UserMonthly, &override.Quotas.UserMonthly},
+		{&merged.Quotas.WorkspaceDaily, &override.Quotas.WorkspaceDaily},
+		{&merged.Quotas.WorkspaceMonthly, &override.Quotas.WorkspaceMonthly},
+	} {
+		if *q.override != nil {
+			*q.into = *q.override
+		}
+	}
+	for _, m := range []struct{ into, base, override map[string]string }{
+		{merged.Prompts, base.Prompts, override.Prompts},
+		{merged.Tools, base.Tools, override.Tools},
+	} {
+		for key, value := range m.base {
+			m.into[key] = value
+		}
+		for key, value := range m.override {
+			if value != "" {
+				m.into[key] = value
+			}
+		}
+	}
+	return merged
+}
+
+func mergeSettings(base, override models.AIModelSettings) models.AIModelSettings {
+	if override.Name != "" {
+		base.Name = override.Name
+	}
+	if override.Temperature != nil {
+		base.Temperature = override.Temperature
+	}
+	if override.MaxOutputTokens != nil {
+		base.MaxOutputTokens = override.MaxOutputTokens
+	}
+	if len(override.Safety) > 0 {
+		safety := map[string]string{}
+		for category, threshold := range base.Safety {
+			safety[category] = threshold
+		}
+		for category, threshold := range override.Safety {
+			safety[category] = threshold
+		}
+		base.Safety = safety
+	}
+	return base
+}
+
+// Settings returns the model settings for a use, filled in from the default entry
+func Settings(config models.AIConfig, use string) models.AIModelSettings {
+	return mergeSettings(config.Models[UseDefault], config.Models[use])
+}
+
+// Validate checks that a config only names known uses, prompts and tools, that
+// its templates render and that its settings are in range
+func Validate(config models.AIConfig) error {
+	for use, settings := range config.Models {
+		if use != UseDefault && !contains(uses(), use) {
+			return fmt.Errorf("unknown model use %q", use)
+		}
+		if settings.Temperature != nil && (*settings.Temperature < 0 || *settings.Temperature > 2) {
+			return fmt.Errorf("temperature for %s must be between 0 and 2", use)
+		}
+		if settings.MaxOutputTokens != nil && *settings.MaxOutputTokens <= 0 {
+			return fmt.Errorf("max_output_tokens for %s must be positive", use)
+		}
+		for category, threshold := range settings.Safety {
+			if !contains(SafetyCategories, category) {
+				return fmt.Errorf("unknown safety category %q", category)
+			}
+			if !contains(SafetyThresholds, threshold) {
+				return fmt.Errorf("unknown safety threshold %q", threshold)
+			}
+		}
+	}
+	for name, text := range config.Prompts {
+		names, ok := promptVars[name]
+		if !ok {
+			return fmt.Errorf("unknown prompt %q", name)
+		}
+		vars := map[string]interface{}{}
+		for _, v := range append(append([]string{}, commonVars...), names...) {
+			vars[v] = "example"
+		}
+		if _, err := render(name, text, vars); err != nil {
+			return fmt.Errorf("prompt %s: %v", name, err)
+		}
+	}
+	for name := range config.Tools {
+		if _, ok := defaults.Tools[name]; !ok {
+			return fmt.Errorf("unknown tool %q", name)
+		}
+	}
+	for model, cost := range config.Costs {
+		if cost.InputPerMillion < 0 || cost.OutputPerMillion < 0 {
+			return fmt.Errorf("cost of %s can't be negative", model)
+		}
+	}
+	for _, quota := range []*int64{config.Quotas.UserDaily, config.Quotas.UserMonthly, config.Quotas.WorkspaceDaily, config.Quotas.WorkspaceMonthly} {
+		if quota != nil && *quota < 0 {
+			return fmt.Errorf("quotas can't be negative")
+		}
+	}
+	return nil
+}
+
+// Render fills in a prompt template. A template that fails at call time falls
+// back to the built-in one rather than failing the AI call.
+func Render(config models.AIConfig, name string, vars map[string]interface{}) string {
+	if text, ok := config.Prompts[name]; ok {
+		out, err := render(name, text, vars)
+		if err == nil {
+			return out
+		}
+		log.Printf("Prompt %s failed to render, using the default: %v", name, err)
+	}
+	out, err := render(name, defaults.Prompts[name], vars)
+	if err != nil {
+		log.Printf("Default prompt %s failed to render: %v", name, err)
+	}
+	return out
+}
+
+func render(name, text string, vars map[string]interface{}) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, vars); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+type scopeKey struct{}
+
+// Scope is who an AI call is made for. It selects the workspace overrides and
+// fills in the UserName and Workspace prompt variables.
+type Scope struct {
+	UserID      *primitive.ObjectID
+	WorkspaceID *primitive.ObjectID
+}
+
+// WithScope attaches the user and workspace an AI call is made for to ctx
+func WithScope(ctx context.Context, userID *primitive.ObjectID, workspaceID *primitive.ObjectID) context.Context {
+	return context.WithValue(ctx, scopeKey{}, Scope{UserID: userID, WorkspaceID: workspaceID})
+}
+
+func ScopeFrom(ctx context.Context) Scope {
+	scope, _ := ctx.Value(scopeKey{}).(Scope)
+	return scope
+}
+
+// Resolve returns the config for a call: the base config, then the active
+// global version, then the active version of the scope's workspace. Versions
+// that can't be loaded are skipped so AI calls keep working.
+func Resolve(ctx context.Context) models.AIConfig {
+	config := Base()
+	// Without a database, e.g. in the eval harness, only the file and env apply
+	if database.DB == nil {
+		return config
+	}
+	scope := ScopeFrom(ctx)
+	active, err := activeVersions(ctx, scope.WorkspaceID)
+	if err != nil {
+		log.Printf("Could not load AI config versions: %v", err)
+		return config
+	}
+	for _, version := range active {
+		config = Merge(config, version.Config)
+	}
+	return config
+}
+
+// Vars returns the common prompt variables for the call's scope
+func Vars(ctx context.Context) map[string]interface{} {
+	now := time.Now()
+	vars := map[string]interface{}{
+		"UserName":  "",
+		"Date":      now.Format("Monday 2006-01-02"),
+		"Time":      now.Format("15:04"),
+		"Workspace": "",
+	}
+	scope := ScopeFrom(ctx)
+	if scope.UserID != nil {
+		var user models.UserRequest
+		if err := database.GetCollection("user").FindOne(ctx, bson.M{"_id": *scope.UserID}).Decode(&user); err == nil {
+			vars["UserName"] = user.Name
+		}
+	}
+	if scope.WorkspaceID != nil {
+		var workspace models.Workspace
+		if err := database.GetCollection("workspace").FindOne(ctx, bson.M{"_id": *scope.WorkspaceID}).Decode(&workspace); err == nil {
+			vars["Workspace"] = workspace.Name
+		}
+	}
+	return vars
+}
+
+// activeVersions returns the active global version and, with a workspace, the
+// workspace's active version, in the order they apply
+func activeVersions(ctx context.Context, workspaceID *primitive.ObjectID) ([]models.AIConfigVersion, error) {
+	scopes := []bson.M{{"workspace_id": nil}}
+	if workspaceID != nil {
+		scopes = append(scopes, bson.M{"workspace_id": *workspaceID})
+	}
+	cursor, err := database.GetCollection("ai_config").Find(ctx, bson.M{"active": true, "$or": scopes})
+	if err != nil {
+		return nil, err
+	}
+	var versions []models.AIConfigVersion
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	// Global first so the workspace version wins
+	if len(versions) == 2 && versions[0].WorkspaceID != nil {
+		versions[0], versions[1] = versions[1], versions[0]
+	}
+	return versions, nil
+}
+
+// ActiveVersion returns the version number applied for a workspace (nil for
+// global), or 0 when only the base config applies
+func ActiveVersion(ctx context.Context, workspaceID *primitive.ObjectID) (int, error) {
+	var version models.AIConfigVersion
+	err := database.GetCollection("ai_config").FindOne(ctx, bson.M{"workspace_id": workspaceID, "active": true}).Decode(&version)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("could not fetch AI config: %v", err)
+	}
+	return version.Version, nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package aiconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrVersionNotFound is returned by Activate for a version that doesn't exist
+var ErrVersionNotFound = errors.New("config version not found")
+
+// Versions lists the saved versions of the global (nil) or a workspace's config, newest first
+func Versions(ctx context.Context, workspaceID *primitive.ObjectID) ([]models.AIConfigVersion, error) {
+	cursor, err := database.GetCollection("ai_config").Find(ctx, bson.M{"workspace_id": workspaceID},
+		options.Find().SetSort(bson.M{"version": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch AI config versions: %v", err)
+	}
+	versions := []models.AIConfigVersion{}
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, fmt.Errorf("failed to parse AI config versions: %v", err)
+	}
+	return versions, nil
+}
+
+// maxSaveAttempts bounds the retries when concurrent saves pick the same
+// version number
+const maxSaveAttempts = 5
+
+// Save validates a config and stores it as the next version, making it active.
+// It runs in a transaction where the server supports one. Either way the unique
+// indexes on the version number and the active version refuse a concurrent
+// save that raced this one, which is then retried on top of it.
+func Save(ctx context.Context, workspaceID *primitive.ObjectID, config models.AIConfig, note string, userID primitive.ObjectID) (models.AIConfigVersion, error) {
+	if err := Validate(config); err != nil {
+		return models.AIConfigVersion{}, err
+	}
+	collection := database.GetCollection("ai_config")
+	var version models.AIConfigVersion
+	save := func(ctx context.Context) error {
+		var latest models.AIConfigVersion
+		err := collection.FindOne(ctx, bson.M{"workspace_id": workspaceID}, options.FindOne().SetSort(bson.M{"version": -1})).Decode(&latest)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
+		}
+		version = models.AIConfigVersion{
+			ID:          primitive.NewObjectID(),
+			WorkspaceID: workspaceID,
+			Version:     latest.Version + 1,
+			Config:      config,
+			Note:        note,
+			Active:      true,
+			CreatedBy:   userID,
+			CreatedAt:   time.Now(),
+		}
+		if _, err := collection.UpdateMany(ctx, bson.M{"workspace_id": workspaceID, "active": true}, bson.M{"$set": bson.M{"active": false}}); err != nil {
+			return err
+		}
+		_, err = collection.InsertOne(ctx, version)
+		return err
+	}
+
+	var err error
+	for attempt := 0; attempt < maxSaveAttempts; attempt++ {
+		err = database.WithTransaction(ctx, save)
+		if err == database.ErrNoTransactions {
+			err = save(ctx)
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			break
+		}
+	}
+	if err != nil {
+		return models.AIConfigVersion{}, fmt.Errorf("could not save AI config: %v", err)
+	}
+	return version, nil
+}
+
+// Activate makes an earlier version the applied one, e.g. to roll back a bad
+// prompt. Version 0 deactivates all versions so only the base config applies.
+func Activate(ctx context.Context, workspaceID *primitive.ObjectID, version int) error {
+	collection := database.GetCollection("ai_config")
+	if version != 0 {
+		count, err := collection.CountDocuments(ctx, bson.M{"workspace_id": workspaceID, "version": version})
+		if err != nil {
+			return fmt.Errorf("could not fetch AI config: %v", err)
+		}
+		if count == 0 {
+			return ErrVersionNotFound
+		}
+	}
+	activate := func(ctx context.Context) error {
+		if _, err := collection.UpdateMany(ctx, bson.M{"workspace_id": workspaceID, "active": true}, bson.M{"$set": bson.M{"active": false}}); err != nil {
+			return err
+		}
+		if version == 0 {
+			return nil
+		}
+		_, err := collection.UpdateOne(ctx, bson.M{"workspace_id": workspaceID, "version": version}, bson.M{"$set": bson.M{"active": true}})
+		return err
+	}
+	err := database.WithTransaction(ctx, activate)
+	if err == database.ErrNoTransactions {
+		err = activate(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("could not update AI config: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Package aieval replays golden conversations through the chat assistant and
+// scores the tool calls the model makes against the expected ones.
+package aieval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Case is one recorded conversation
+type Case struct {
+	Name  string `json:"name"`
+	Turns []Turn `json:"turns"`
+
+	// path is the file the case was loaded from, for re-recording
+	path string
+}
+
+// Turn is one user message, the tool calls it should produce and the model
+// replies recorded for it
+type Turn struct {
+	User   string `json:"user"`
+	Expect []Call `json:"expect"`
+	// Responses are the model's replies in order: one to the user message and
+	// one to each tool result after it
+	Responses []Response `json:"responses,omitempty"`
+	// ToolResults are returned to the model instead of running the tools.
+	// Tools without a result get {"success": true}.
+	ToolResults map[string]map[string]interface{} `json:"tool_results,omitempty"`
+}
+
+// Call is a tool call. In expectations each argument is either a value that
+// must match exactly (strings ignore case) or a matcher object:
+// {"contains": "text"}, {"one_of": [...]} or {"any": true}.
+type Call struct {
+	Name string                 `json:"name"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+// Response is one model reply
+type Response struct {
+	Text          string `json:"text,omitempty"`
+	FunctionCalls []Call `json:"function_calls,omitempty"`
+}
+
+// LoadCorpus reads every *.json case in dir, sorted by file name
+func LoadCorpus(dir string) ([]*Case, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	var cases []*Case
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var c Case
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if c.Name == "" {
+			c.Name = filepath.Base(path)
+		}
+		if len(c.Turns) == 0 {
+			return nil, fmt.Errorf("%s: case has no turns", path)
+		}
+		c.path = path
+		cases = append(cases, &c)
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("no cases in %s", dir)
+	}
+	return cases, nil
+}
+
+// Save writes the case back to the file it was loaded from
+func (c *Case) Save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,239 @@
+package aieval
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	aigenai "github.com/Atif-27/ai-task-manager/genai"
+)
+
+// Options control how the cases are run
+type Options struct {
+	// Live sends the user turns to this provider instead of replaying the
+	// recorded responses
+	Live aigenai.Provider
+	// Record replaces each case's recorded responses with the live ones. It
+	// needs Live.
+	Record bool
+}
+
+// Score counts tool selection and argument extraction results
+type Score struct {
+	// Called is the number of tool calls the model made, Expected the number
+	// the cases expect and Matched the calls with an expected tool name
+	Called   int `json:"called"`
+	Expected int `json:"expected"`
+	Matched  int `json:"matched"`
+	// ArgsChecked is the number of expected arguments on matched calls and
+	// ArgsCorrect how many of them the model got right
+	ArgsChecked int `json:"args_checked"`
+	ArgsCorrect int `json:"args_correct"`
+}
+
+func (s *Score) add(other Score) {
+	s.Called += other.Called
+	s.Expected += other.Expected
+	s.Matched += other.Matched
+	s.ArgsChecked += other.ArgsChecked
+	s.ArgsCorrect += other.ArgsCorrect
+}
+
+// ratio is part/whole, or 1 when there was nothing to get wrong
+func ratio(part, whole int) float64 {
+	if whole == 0 {
+		return 1
+	}
+	return float64(part) / float64(whole)
+}
+
+// Precision is the share of tool calls the model made that were expected
+func (s Score) Precision() float64 {
+	return ratio(s.Matched, s.Called)
+}
+
+// Recall is the share of expected tool calls the model made
+func (s Score) Recall() float64 {
+	return ratio(s.Matched, s.Expected)
+}
+
+// ArgAccuracy is the share of expected arguments extracted correctly
+func (s Score) ArgAccuracy() float64 {
+	return ratio(s.ArgsCorrect, s.ArgsChecked)
+}
+
+// CaseResult is the score of one case and what went wrong in it
+type CaseResult struct {
+	Name     string   `json:"name"`
+	Score    Score    `json:"score"`
+	Failures []string `json:"failures,omitempty"`
+}
+
+// Report is the outcome of a run
+type Report struct {
+	Cases []CaseResult `json:"cases"`
+	Total Score        `json:"total"`
+}
+
+// Run replays every case through the assistant's conversation loop and scores
+// the tool calls made on each turn
+func Run(ctx context.Context, cases []*Case, opts Options) (Report, error) {
+	if opts.Record && opts.Live == nil {
+		return Report{}, fmt.Errorf("recording needs a live provider")
+	}
+	var report Report
+	for i, c := range cases {
+		result, err := runCase(ctx, c, opts, fmt.Sprintf("eval-%d", i))
+		if err != nil {
+			return report, fmt.Errorf("%s: %v", c.Name, err)
+		}
+		report.Cases = append(report.Cases, result)
+		report.Total.add(result.Score)
+	}
+	return report, nil
+}
+
+func runCase(ctx context.Context, c *Case, opts Options, userID string) (CaseResult, error) {
+	result := CaseResult{Name: c.Name}
+	tools := &cannedTools{}
+	var provider aigenai.Provider = newReplayProvider(c)
+	var recorder *recordingProvider
+	if opts.Live != nil {
+		recorder = &recordingProvider{provider: opts.Live}
+		provider = recorder
+	}
+	// Each case gets its own manager so conversations don't share history
+	sm := aigenai.NewSessionManager(provider, tools)
+
+	for i := range c.Turns {
+		turn := &c.Turns[i]
+		if recorder != nil && opts.Record {
+			turn.Responses = nil
+			recorder.turn = turn
+		}
+		tools.turn = turn
+
+		// Calls the guardrails refused still count: the model made them
+		reply, err := sm.Process(ctx, turn.User, userID)
+		if err != nil {
+			result.Failures = append(result.Failures, fmt.Sprintf("turn %d: %v", i+1, err))
+		}
+		var calls []Call
+		for _, step := range reply.Steps {
+			calls = append(calls, Call{Name: step.Tool, Args: step.Args})
+		}
+		score, failures := scoreTurn(turn.Expect, calls)
+		result.Score.add(score)
+		for _, failure := range failures {
+			result.Failures = append(result.Failures, fmt.Sprintf("turn %d: %s", i+1, failure))
+		}
+	}
+
+	if opts.Record {
+		if err := c.Save(); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// scoreTurn pairs each expected call with the unused actual call of the same
+// tool that matches the most arguments and checks its arguments. Calls made in
+// parallel can arrive in any order.
+func scoreTurn(expected []Call, actual []Call) (Score, []string) {
+	score := Score{Called: len(actual), Expected: len(expected)}
+	var failures []string
+	used := make([]bool, len(actual))
+	for _, want := range expected {
+		found, best := -1, -1
+		for i, got := range actual {
+			if used[i] || got.Name != want.Name {
+				continue
+			}
+			if matched := matchingArgs(want, got); matched > best {
+				found, best = i, matched
+			}
+		}
+		if found < 0 {
+			failures = append(failures, fmt.Sprintf("expected a %s call", want.Name))
+			continue
+		}
+		used[found] = true
+		score.Matched++
+		for arg, matcher := range want.Args {
+			score.ArgsChecked++
+			value, ok := actual[found].Args[arg]
+			if ok && matchArg(matcher, value) {
+				score.ArgsCorrect++
+				continue
+			}
+			failures = append(failures, fmt.Sprintf("%s.%s: got %v, want %v", want.Name, arg, value, matcher))
+		}
+	}
+	for i, got := range actual {
+		if !used[i] {
+			failures = append(failures, fmt.Sprintf("unexpected %s call", got.Name))
+		}
+	}
+	return score, failures
+}
+
+// matchingArgs counts the expected arguments a call got right
+func matchingArgs(want Call, got Call) int {
+	matched := 0
+	for arg, matcher := range want.Args {
+		if value, ok := got.Args[arg]; ok && matchArg(matcher, value) {
+			matched++
+		}
+	}
+	return matched
+}
+
+// matchArg checks an argument the model gave against an expectation
+func matchArg(matcher interface{}, value interface{}) bool {
+	if m, ok := matcher.(map[string]interface{}); ok {
+		if substr, ok := m["contains"].(string); ok {
+			s, isString := value.(string)
+			return isString && strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+		}
+		if options, ok := m["one_of"].([]interface{}); ok {
+			for _, option := range options {
+				if matchArg(option, value) {
+					return true
+				}
+			}
+			return false
+		}
+		if any, _ := m["any"].(bool); any {
+			return value != nil && value != ""
+		}
+	}
+	if want, ok := matcher.(string); ok {
+		s, isString := value.(string)
+		return isString && strings.EqualFold(strings.TrimSpace(s), strings.TrimSpace(want))
+	}
+	// JSON numbers decode as float64 on both sides
+	return reflect.DeepEqual(matcher, value)
+}
+
+// Print writes a per-case table and the totals
+func (r Report) Print(w io.Writer) {
+	for _, c := range r.Cases {
+		status := "ok"
+		if len(c.Failures) > 0 {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%-4s %-50s precision %.2f  recall %.2f  args %.2f\n",
+			status, c.Name, c.Score.Precision(), c.Score.Recall(), c.Score.ArgAccuracy())
+		for _, failure := range c.Failures {
+			fmt.Fprintf(w, "       %s\n", failure)
+		}
+	}
+	fmt.Fprintf(w, "\n%d cases: tool precision %.2f (%d/%d), recall %.2f (%d/%d), argument accuracy %.2f (%d/%d)\n",
+		len(r.Cases),
+		r.Total.Precision(), r.Total.Matched, r.Total.Called,
+		r.Total.Recall(), r.Total.Matched, r.Total.Expected,
+		r.Total.ArgAccuracy(), r.Total.ArgsCorrect, r.Total.ArgsChecked)
+}
@@ -0,0 +1,60 @@
+package aieval
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/genai"
+)
+
+// The corpus fails the build when the assistant's tool selection or argument
+// extraction drops below these
+const (
+	minPrecision   = 0.9
+	minArgAccuracy = 0.9
+)
+
+// TestCorpus replays the golden conversations through the assistant's
+// conversation loop. With AIEVAL_LIVE=1 the turns go to the configured model
+// instead, which needs API_KEY and is never done by default.
+func TestCorpus(t *testing.T) {
+	if err := aiconfig.Load(); err != nil {
+		t.Fatalf("AI config: %v", err)
+	}
+	cases, err := LoadCorpus("corpus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("the corpus is empty")
+	}
+
+	ctx := context.Background()
+	var opts Options
+	if os.Getenv("AIEVAL_LIVE") == "1" {
+		provider, closeProvider, err := genai.NewGeminiProvider(ctx)
+		if err != nil {
+			t.Fatalf("Gemini: %v", err)
+		}
+		defer closeProvider()
+		opts.Live = provider
+	}
+
+	report, err := Run(ctx, cases, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	report.Print(&out)
+	t.Log("\n" + out.String())
+
+	if precision := report.Total.Precision(); precision < minPrecision {
+		t.Errorf("tool precision %.2f is below %.2f", precision, minPrecision)
+	}
+	if accuracy := report.Total.ArgAccuracy(); accuracy < minArgAccuracy {
+		t.Errorf("argument accuracy %.2f is below %.2f", accuracy, minArgAccuracy)
+	}
+}
@@ -0,0 +1,107 @@
+package aieval
+
+import (
+	"context"
+	"fmt"
+
+	aigenai "github.com/Atif-27/ai-task-manager/genai"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// replayProvider serves a case's recorded responses in order
+type replayProvider struct {
+	responses []Response
+}
+
+func newReplayProvider(c *Case) *replayProvider {
+	p := &replayProvider{}
+	for _, turn := range c.Turns {
+		p.responses = append(p.responses, turn.Responses...)
+	}
+	return p
+}
+
+func (p *replayProvider) StartChat(ctx context.Context, userID string) (aigenai.ChatSession, string, error) {
+	return p, "recorded", nil
+}
+
+func (p *replayProvider) SendMessage(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+	if len(p.responses) == 0 {
+		return nil, fmt.Errorf("recorded conversation has no more responses")
+	}
+	next := p.responses[0]
+	p.responses = p.responses[1:]
+	return toGenerateContentResponse(next), nil
+}
+
+// recordingProvider wraps a live provider and appends every reply to the turn
+// being evaluated
+type recordingProvider struct {
+	provider aigenai.Provider
+	turn     *Turn
+}
+
+func (p *recordingProvider) StartChat(ctx context.Context, userID string) (aigenai.ChatSession, string, error) {
+	chat, modelName, err := p.provider.StartChat(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	return &recordingSession{chat: chat, provider: p}, modelName, nil
+}
+
+type recordingSession struct {
+	chat     aigenai.ChatSession
+	provider *recordingProvider
+}
+
+func (s *recordingSession) SendMessage(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+	resp, err := s.chat.SendMessage(ctx, parts...)
+	if err == nil && s.provider.turn != nil {
+		s.provider.turn.Responses = append(s.provider.turn.Responses, fromGenerateContentResponse(resp))
+	}
+	return resp, err
+}
+
+func toGenerateContentResponse(r Response) *genai.GenerateContentResponse {
+	var parts []genai.Part
+	if r.Text != "" {
+		parts = append(parts, genai.Text(r.Text))
+	}
+	for _, call := range r.FunctionCalls {
+		parts = append(parts, genai.FunctionCall{Name: call.Name, Args: call.Args})
+	}
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: &genai.Content{Role: "model", Parts: parts}}},
+	}
+}
+
+func fromGenerateContentResponse(resp *genai.GenerateContentResponse) Response {
+	var r Response
+	for _, cand := range resp.Candidates {
+		if cand.Content == nil {
+			continue
+		}
+		for _, part := range cand.Content.Parts {
+			switch part := part.(type) {
+			case genai.Text:
+				r.Text += string(part)
+			case genai.FunctionCall:
+				r.FunctionCalls = append(r.FunctionCalls, Call{Name: part.Name, Args: part.Args})
+			}
+		}
+	}
+	return r
+}
+
+// cannedTools answers tool calls with the current turn's canned results, so
+// nothing touches the task store
+type cannedTools struct {
+	turn *Turn
+}
+
+func (r *cannedTools) RunTool(ctx context.Context, session *aigenai.UserSession, call genai.FunctionCall) (map[string]interface{}, error) {
+	if result, ok := r.turn.ToolResults[call.Name]; ok {
+		return result, nil
+	}
+	return map[string]interface{}{"success": true}, nil
+}
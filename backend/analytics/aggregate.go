@@ -0,0 +1,168 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// historyAt returns the time of the first (index 0) or last (index -1) status
+// history entry with the given status
+func historyAt(status models.StatusType, index int) bson.M {
+	return bson.M{"$let": bson.M{
+		"vars": bson.M{"change": bson.M{"$arrayElemAt": bson.A{
+			bson.M{"$filter": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$status_history", bson.A{}}},
+				"as":    "c",
+				"cond":  bson.M{"$eq": bson.A{"$$c.status", status}},
+			}},
+			index,
+		}}},
+		"in": "$$change.at",
+	}}
+}
+
+// nearestRank picks the p-th percentile of the sorted "hours" array
+func nearestRank(p float64) bson.M {
+	rank := bson.M{"$subtract": bson.A{bson.M{"$ceil": bson.M{"$multiply": bson.A{p, "$count"}}}, 1}}
+	return bson.M{"$arrayElemAt": bson.A{"$hours", bson.M{"$max": bson.A{rank, 0}}}}
+}
+
+// Aggregate computes the report with a single $facet pipeline over the task
+// collection. Compute gives the same result from tasks in memory.
+func Aggregate(ctx context.Context, opts Options) (Report, error) {
+	if err := opts.Validate(); err != nil {
+		return Report{}, err
+	}
+	format := "%Y-%m-%d"
+	if opts.Interval == IntervalWeek {
+		format = "%G-W%V"
+	}
+	periodOf := func(field string) bson.M {
+		return bson.M{"$dateToString": bson.M{"format": format, "date": field, "timezone": "UTC"}}
+	}
+	inPeriod := bson.M{"$gte": opts.From, "$lt": opts.To}
+	overdue := bson.M{"$and": bson.A{
+		bson.M{"$eq": bson.A{bson.M{"$type": "$due_date"}, "date"}},
+		bson.M{"$lt": bson.A{"$due_date", opts.Now}},
+	}}
+	// tasks without a priority are counted as "none", whether it is missing or empty
+	priority := bson.M{"$cond": bson.A{
+		bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$priority", ""}}, ""}}, "none", "$priority",
+	}}
+	open := bson.M{"$match": bson.M{"status": bson.M{"$ne": models.COMPLETED}}}
+	completed := []bson.M{
+		{"$match": bson.M{"status": models.COMPLETED}},
+		{"$addFields": bson.M{"done": historyAt(models.COMPLETED, -1), "started": historyAt(models.INPROGRESS, 0)}},
+		{"$match": bson.M{"done": inPeriod}},
+	}
+
+	match := trash.Live(bson.M{})
+	if opts.WorkspaceID != nil {
+		match["workspace_id"] = *opts.WorkspaceID
+	}
+	pipeline := bson.A{
+		bson.M{"$match": match},
+		bson.M{"$facet": bson.M{
+			"created": bson.A{
+				bson.M{"$match": bson.M{"created_at": inPeriod}},
+				bson.M{"$group": bson.M{"_id": periodOf("$created_at"), "count": bson.M{"$sum": 1}}},
+			},
+			"completed": append(bson.A{completed[0], completed[1], completed[2]},
+				bson.M{"$group": bson.M{"_id": periodOf("$done"), "count": bson.M{"$sum": 1}}},
+			),
+			"cycle_time": append(bson.A{completed[0], completed[1], completed[2]},
+				bson.M{"$match": bson.M{"started": bson.M{"$ne": nil}, "$expr": bson.M{"$lt": bson.A{"$started", "$done"}}}},
+				bson.M{"$project": bson.M{"hours": bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{"$done", "$started"}}, 3600000}}}},
+				bson.M{"$sort": bson.M{"hours": 1}},
+				bson.M{"$group": bson.M{"_id": nil, "hours": bson.M{"$push": "$hours"}, "count": bson.M{"$sum": 1}, "average_hours": bson.M{"$avg": "$hours"}}},
+				bson.M{"$project": bson.M{"count": 1, "average_hours": 1, "p50_hours": nearestRank(0.5), "p90_hours": nearestRank(0.9)}},
+			),
+			"workload": bson.A{
+				open,
+				bson.M{"$unwind": "$assigned_to"},
+				bson.M{"$group": bson.M{
+					"_id":     bson.M{"user": "$assigned_to", "priority": priority},
+					"open":    bson.M{"$sum": 1},
+					"overdue": bson.M{"$sum": bson.M{"$cond": bson.A{overdue, 1, 0}}},
+				}},
+				bson.M{"$group": bson.M{
+					"_id":         "$_id.user",
+					"open":        bson.M{"$sum": "$open"},
+					"overdue":     bson.M{"$sum": "$overdue"},
+					"by_priority": bson.M{"$push": bson.M{"k": "$_id.priority", "v": "$open"}},
+				}},
+				bson.M{"$project": bson.M{"open": 1, "overdue": 1, "by_priority": bson.M{"$arrayToObject": "$by_priority"}}},
+			},
+			"totals": bson.A{
+				open,
+				bson.M{"$group": bson.M{
+					"_id":     nil,
+					"open":    bson.M{"$sum": 1},
+					"overdue": bson.M{"$sum": bson.M{"$cond": bson.A{overdue, 1, 0}}},
+				}},
+			},
+		}},
+	}
+
+	cursor, err := database.GetCollection("task").Aggregate(ctx, pipeline)
+	if err != nil {
+		return Report{}, fmt.Errorf("analytics aggregation failed: %v", err)
+	}
+	type count struct {
+		Period string `bson:"_id"`
+		Count  int    `bson:"count"`
+	}
+	var results []struct {
+		Created   []count     `bson:"created"`
+		Completed []count     `bson:"completed"`
+		CycleTime []CycleTime `bson:"cycle_time"`
+		Workload  []Workload  `bson:"workload"`
+		Totals    []struct {
+			Open    int `bson:"open"`
+			Overdue int `bson:"overdue"`
+		} `bson:"totals"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return Report{}, fmt.Errorf("could not decode analytics: %v", err)
+	}
+	// $facet always produces exactly one document
+	if len(results) != 1 {
+		return Report{}, fmt.Errorf("analytics aggregation returned %d documents", len(results))
+	}
+	result := results[0]
+
+	report := Report{From: opts.From, To: opts.To, Interval: opts.Interval, Throughput: []Bucket{}, Workload: result.Workload}
+	buckets := map[string]*Bucket{}
+	for _, c := range result.Created {
+		buckets[c.Period] = &Bucket{Period: c.Period, Created: c.Count}
+	}
+	for _, c := range result.Completed {
+		if buckets[c.Period] == nil {
+			buckets[c.Period] = &Bucket{Period: c.Period}
+		}
+		buckets[c.Period].Completed = c.Count
+	}
+	for _, b := range buckets {
+		report.Throughput = append(report.Throughput, *b)
+	}
+	sort.Slice(report.Throughput, func(i, j int) bool { return report.Throughput[i].Period < report.Throughput[j].Period })
+
+	if len(result.CycleTime) > 0 {
+		report.CycleTime = result.CycleTime[0]
+	}
+	if report.Workload == nil {
+		report.Workload = []Workload{}
+	}
+	sortWorkload(report.Workload)
+	if len(result.Totals) > 0 {
+		report.Open = result.Totals[0].Open
+		report.Overdue = result.Totals[0].Overdue
+	}
+	return report, nil
+}
@@ -0,0 +1,233 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Intervals throughput can be bucketed by
+const (
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// Options select the tasks and period the metrics are computed over. Throughput
+// and cycle time cover From-To; workload and overdue counts are a snapshot at Now.
+type Options struct {
+	From        time.Time
+	To          time.Time
+	Now         time.Time
+	Interval    string
+	WorkspaceID *primitive.ObjectID
+}
+
+// Bucket is the number of tasks created and completed in one day or ISO week
+type Bucket struct {
+	Period    string `json:"period"`
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+}
+
+// CycleTime is the time from a task first moving to in_progress until it was
+// completed, over the tasks completed in the period
+type CycleTime struct {
+	Count        int     `bson:"count" json:"count"`
+	AverageHours float64 `bson:"average_hours" json:"average_hours"`
+	P50Hours     float64 `bson:"p50_hours" json:"p50_hours"`
+	P90Hours     float64 `bson:"p90_hours" json:"p90_hours"`
+}
+
+// Workload is the open tasks of one assignee
+type Workload struct {
+	UserID     primitive.ObjectID          `bson:"_id" json:"user_id"`
+	Name       string                      `bson:"-" json:"name,omitempty"`
+	Open       int                         `bson:"open" json:"open"`
+	Overdue    int                         `bson:"overdue" json:"overdue"`
+	ByPriority map[models.PriorityType]int `bson:"by_priority" json:"by_priority"`
+}
+
+type Report struct {
+	From       time.Time  `json:"from"`
+	To         time.Time  `json:"to"`
+	Interval   string     `json:"interval"`
+	Throughput []Bucket   `json:"throughput"`
+	CycleTime  CycleTime  `json:"cycle_time"`
+	Workload   []Workload `json:"workload"`
+	Open       int        `json:"open"`
+	Overdue    int        `json:"overdue"`
+}
+
+// Validate fills in defaults and checks the options
+func (o *Options) Validate() error {
+	if o.Now.IsZero() {
+		o.Now = time.Now()
+	}
+	if o.To.IsZero() {
+		o.To = o.Now
+	}
+	if o.From.IsZero() {
+		o.From = o.To.AddDate(0, 0, -30)
+	}
+	if !o.From.Before(o.To) {
+		return fmt.Errorf("from must be before to")
+	}
+	if o.Interval == "" {
+		o.Interval = IntervalDay
+	}
+	if o.Interval != IntervalDay && o.Interval != IntervalWeek {
+		return fmt.Errorf("interval must be day or week")
+	}
+	return nil
+}
+
+// period formats t the same way as the $dateToString formats used by Aggregate
+func period(t time.Time, interval string) string {
+	t = t.UTC()
+	if interval == IntervalWeek {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	}
+	return t.Format("2006-01-02")
+}
+
+// startedAt is when the task first moved to in_progress
+func startedAt(task models.Task) (time.Time, bool) {
+	for _, change := range task.StatusHistory {
+		if change.Status == models.INPROGRESS {
+			return change.At, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// completedAt is when a completed task last moved to completed
+func completedAt(task models.Task) (time.Time, bool) {
+	if task.Status != models.COMPLETED {
+		return time.Time{}, false
+	}
+	for i := len(task.StatusHistory) - 1; i >= 0; i-- {
+		if task.StatusHistory[i].Status == models.COMPLETED {
+			return task.StatusHistory[i].At, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// percentile uses the nearest-rank method on sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func inRange(t time.Time, opts Options) bool {
+	return !t.Before(opts.From) && t.Before(opts.To)
+}
+
+// Compute is the in-memory equivalent of Aggregate, for when the tasks are
+// already loaded or the server can't run the pipelines
+func Compute(tasks []models.Task, opts Options) (Report, error) {
+	if err := opts.Validate(); err != nil {
+		return Report{}, err
+	}
+	report := Report{From: opts.From, To: opts.To, Interval: opts.Interval, Throughput: []Bucket{}, Workload: []Workload{}}
+
+	buckets := map[string]*Bucket{}
+	bucket := func(t time.Time) *Bucket {
+		key := period(t, opts.Interval)
+		if buckets[key] == nil {
+			buckets[key] = &Bucket{Period: key}
+		}
+		return buckets[key]
+	}
+	var cycles []float64
+	workload := map[primitive.ObjectID]*Workload{}
+
+	for _, task := range tasks {
+		if task.DeletedAt != nil {
+			continue
+		}
+		if opts.WorkspaceID != nil && (task.WorkspaceID == nil || *task.WorkspaceID != *opts.WorkspaceID) {
+			continue
+		}
+		if inRange(task.CreatedAt, opts) {
+			bucket(task.CreatedAt).Created++
+		}
+		if done, ok := completedAt(task); ok && inRange(done, opts) {
+			bucket(done).Completed++
+			if started, ok := startedAt(task); ok && started.Before(done) {
+				cycles = append(cycles, done.Sub(started).Hours())
+			}
+		}
+
+		if task.Status == models.COMPLETED {
+			continue
+		}
+		overdue := task.DueDate != nil && task.DueDate.Before(opts.Now)
+		report.Open++
+		if overdue {
+			report.Overdue++
+		}
+		for _, userID := range task.AssignedTo {
+			load := workload[userID]
+			if load == nil {
+				load = &Workload{UserID: userID, ByPriority: map[models.PriorityType]int{}}
+				workload[userID] = load
+			}
+			priority := task.Priority
+			if priority == "" {
+				priority = "none"
+			}
+			load.Open++
+			load.ByPriority[priority]++
+			if overdue {
+				load.Overdue++
+			}
+		}
+	}
+
+	for _, b := range buckets {
+		report.Throughput = append(report.Throughput, *b)
+	}
+	sort.Slice(report.Throughput, func(i, j int) bool { return report.Throughput[i].Period < report.Throughput[j].Period })
+
+	if len(cycles) > 0 {
+		sort.Float64s(cycles)
+		var sum float64
+		for _, hours := range cycles {
+			sum += hours
+		}
+		report.CycleTime = CycleTime{
+			Count:        len(cycles),
+			AverageHours: sum / float64(len(cycles)),
+			P50Hours:     percentile(cycles, 0.5),
+			P90Hours:     percentile(cycles, 0.9),
+		}
+	}
+
+	for _, load := range workload {
+		report.Workload = append(report.Workload, *load)
+	}
+	sortWorkload(report.Workload)
+	return report, nil
+}
+
+// sortWorkload puts the busiest assignees first
+func sortWorkload(workload []Workload) {
+	sort.Slice(workload, func(i, j int) bool {
+		if workload[i].Open != workload[j].Open {
+			return workload[i].Open > workload[j].Open
+		}
+		return workload[i].UserID.Hex() < workload[j].UserID.Hex()
+	})
+}
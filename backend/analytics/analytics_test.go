@@ -0,0 +1,244 @@
+package analytics
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// now is the fixed "now" of the tests: Wednesday 12 March 2025, 10:00 UTC
+var now = time.Date(2025, time.March, 12, 10, 0, 0, 0, time.UTC)
+
+func date(month time.Month, day, hour int) time.Time {
+	return time.Date(2025, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func due(month time.Month, day int) *time.Time {
+	t := date(month, day, 17)
+	return &t
+}
+
+func changed(status models.StatusType, at time.Time) models.StatusChange {
+	return models.StatusChange{Status: status, At: at}
+}
+
+// taskStore points the database package at a throwaway database on the MongoDB
+// at MONGO_URI, dropped when the test ends. It returns nil when MONGO_URI is not set.
+func taskStore(t *testing.T) *mongo.Collection {
+	t.Helper()
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		return nil
+	}
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("MongoDB: %v", err)
+	}
+	t.Setenv("DB_NAME", "analytics_test_"+primitive.NewObjectID().Hex())
+	previous := database.DB
+	database.DB = client
+	tasks := database.GetCollection("task")
+	t.Cleanup(func() {
+		tasks.Database().Drop(ctx)
+		database.DB = previous
+		client.Disconnect(ctx)
+	})
+	return tasks
+}
+
+// TestComputeMatchesAggregate checks Compute against the expected report and,
+// when MONGO_URI is set, that Aggregate gives the same report for the same tasks
+func TestComputeMatchesAggregate(t *testing.T) {
+	store := taskStore(t)
+	workspace, otherWorkspace := primitive.NewObjectID(), primitive.NewObjectID()
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	deleted := date(time.March, 2, 9)
+
+	// completed tasks with cycle times of 24, 12, 288 and 48 hours, one
+	// completed without being started and one completed before the period
+	completions := []models.Task{
+		{
+			Status: models.COMPLETED, CreatedAt: date(time.March, 3, 9),
+			StatusHistory: []models.StatusChange{
+				changed(models.PENDING, date(time.March, 3, 9)),
+				changed(models.INPROGRESS, date(time.March, 3, 10)),
+				changed(models.COMPLETED, date(time.March, 4, 10)),
+			},
+		},
+		{
+			Status: models.COMPLETED, CreatedAt: date(time.March, 3, 12),
+			StatusHistory: []models.StatusChange{
+				changed(models.INPROGRESS, date(time.March, 5, 0)),
+				changed(models.COMPLETED, date(time.March, 5, 12)),
+			},
+		},
+		{
+			Status: models.COMPLETED, CreatedAt: date(time.February, 20, 9),
+			StatusHistory: []models.StatusChange{
+				changed(models.INPROGRESS, date(time.February, 21, 0)),
+				changed(models.COMPLETED, date(time.March, 5, 0)),
+			},
+		},
+		{
+			Status: models.COMPLETED, CreatedAt: date(time.March, 6, 9),
+			StatusHistory: []models.StatusChange{
+				changed(models.COMPLETED, date(time.March, 7, 9)),
+			},
+		},
+		{
+			Status: models.COMPLETED, CreatedAt: date(time.March, 8, 0),
+			StatusHistory: []models.StatusChange{
+				changed(models.INPROGRESS, date(time.March, 8, 1)),
+				changed(models.COMPLETED, date(time.March, 8, 2)),
+				changed(models.INPROGRESS, date(time.March, 9, 0)),
+				changed(models.COMPLETED, date(time.March, 10, 1)),
+			},
+		},
+		{
+			Status: models.COMPLETED, CreatedAt: date(time.February, 24, 9),
+			StatusHistory: []models.StatusChange{
+				changed(models.INPROGRESS, date(time.February, 24, 10)),
+				changed(models.COMPLETED, date(time.February, 25, 10)),
+			},
+		},
+	}
+	cycleTime := CycleTime{Count: 4, AverageHours: 93, P50Hours: 24, P90Hours: 288}
+
+	// open tasks created before the period, plus tasks that must be left out
+	workload := []models.Task{
+		{Status: models.PENDING, Priority: models.HIGH, AssignedTo: []primitive.ObjectID{alice, bob}, DueDate: due(time.March, 11)},
+		{Status: models.INPROGRESS, AssignedTo: []primitive.ObjectID{alice}},
+		{Status: models.PENDING, Priority: models.LOW, AssignedTo: []primitive.ObjectID{alice}, DueDate: due(time.March, 13)},
+		{Status: models.PENDING, DueDate: due(time.March, 1)},
+		{
+			Status: models.COMPLETED, Priority: models.HIGH, AssignedTo: []primitive.ObjectID{bob}, DueDate: due(time.March, 1),
+			StatusHistory: []models.StatusChange{changed(models.COMPLETED, date(time.February, 10, 9))},
+		},
+		{Status: models.PENDING, Priority: models.HIGH, AssignedTo: []primitive.ObjectID{alice}, DeletedAt: &deleted},
+		{Status: models.PENDING, Priority: models.HIGH, AssignedTo: []primitive.ObjectID{alice}, WorkspaceID: &otherWorkspace},
+	}
+	for i := range workload {
+		workload[i].CreatedAt = date(time.February, 1, 9)
+	}
+
+	tests := []struct {
+		name     string
+		tasks    []models.Task
+		interval string
+		want     Report
+	}{
+		{
+			name: "no tasks",
+			want: Report{Throughput: []Bucket{}, Workload: []Workload{}},
+		},
+		{
+			name:  "daily throughput and cycle time",
+			tasks: completions,
+			want: Report{
+				Throughput: []Bucket{
+					{Period: "2025-03-03", Created: 2},
+					{Period: "2025-03-04", Completed: 1},
+					{Period: "2025-03-05", Completed: 2},
+					{Period: "2025-03-06", Created: 1},
+					{Period: "2025-03-07", Completed: 1},
+					{Period: "2025-03-08", Created: 1},
+					{Period: "2025-03-10", Completed: 1},
+				},
+				CycleTime: cycleTime,
+				Workload:  []Workload{},
+			},
+		},
+		{
+			name:     "weekly throughput",
+			tasks:    completions,
+			interval: IntervalWeek,
+			want: Report{
+				Throughput: []Bucket{
+					{Period: "2025-W10", Created: 4, Completed: 4},
+					{Period: "2025-W11", Completed: 1},
+				},
+				CycleTime: cycleTime,
+				Workload:  []Workload{},
+			},
+		},
+		{
+			name:  "workload and overdue tasks",
+			tasks: workload,
+			want: Report{
+				Throughput: []Bucket{},
+				Workload: []Workload{
+					{UserID: alice, Open: 3, Overdue: 1, ByPriority: map[models.PriorityType]int{models.HIGH: 1, models.LOW: 1, "none": 1}},
+					{UserID: bob, Open: 1, Overdue: 1, ByPriority: map[models.PriorityType]int{models.HIGH: 1}},
+				},
+				Open:    4,
+				Overdue: 2,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{
+				From:        date(time.March, 1, 0),
+				To:          date(time.March, 15, 0),
+				Now:         now,
+				Interval:    tt.interval,
+				WorkspaceID: &workspace,
+			}
+			if err := opts.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			tt.want.From, tt.want.To, tt.want.Interval = opts.From, opts.To, opts.Interval
+
+			tasks := make([]models.Task, len(tt.tasks))
+			for i, task := range tt.tasks {
+				task.ID = primitive.NewObjectID()
+				if task.WorkspaceID == nil {
+					task.WorkspaceID = &workspace
+				}
+				tasks[i] = task
+			}
+
+			computed, err := Compute(tasks, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(computed, tt.want) {
+				t.Errorf("Compute = %+v, want %+v", computed, tt.want)
+			}
+
+			if store == nil {
+				t.Skip("MONGO_URI is not set, Aggregate is not compared")
+			}
+			ctx := context.Background()
+			if _, err := store.DeleteMany(ctx, bson.M{}); err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) > 0 {
+				docs := make([]interface{}, len(tasks))
+				for i, task := range tasks {
+					docs[i] = task
+				}
+				if _, err := store.InsertMany(ctx, docs); err != nil {
+					t.Fatal(err)
+				}
+			}
+			aggregated, err := Aggregate(ctx, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(aggregated, computed) {
+				t.Errorf("Aggregate = %+v, Compute = %+v", aggregated, computed)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/Atif-27/ai-task-manager/activity"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ActivityHandler struct{}
+
+// Constructor function for ActivityHandler
+func MakeActivityHandler() *ActivityHandler {
+	return &ActivityHandler{}
+}
+
+// GetActivity lists the latest activity log entries: the caller's own, or with
+// workspace_id everyone's in that workspace
+func (h *ActivityHandler) GetActivity(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	workspaceID, err := optionalWorkspace(c, c.Query("workspace_id"), userID)
+	if err != nil {
+		return sendError(c, err)
+	}
+	limit, err := strconv.ParseInt(c.Query("limit", "50"), 10, 64)
+	if err != nil || limit < 1 || limit > 500 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "limit must be between 1 and 500"})
+	}
+
+	filter := bson.M{"actor_id": userID}
+	if workspaceID != nil {
+		filter = bson.M{"workspace_ids": *workspaceID}
+	}
+	entries, err := activity.List(c.Context(), filter, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch activity"})
+	}
+	return c.JSON(fiber.Map{"activity": entries})
+}
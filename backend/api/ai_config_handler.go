@@ -0,0 +1,162 @@
+package api
+
+import (
+	"os"
+	"strings"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AIConfigHandler struct{}
+
+// Constructor function for AIConfigHandler
+func MakeAIConfigHandler() *AIConfigHandler {
+	return &AIConfigHandler{}
+}
+
+// aiAdminScope checks the caller may manage the AI config or usage named by
+// workspace_id: the global scope needs an admin (ADMIN_EMAILS), a workspace its
+// owner or an admin.
+func aiAdminScope(c *fiber.Ctx, value string) (*primitive.ObjectID, error) {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	admin, err := isAdmin(c, userID)
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		if !admin {
+			return nil, &requestError{fiber.StatusForbidden, "Only admins can do this for all workspaces"}
+		}
+		return nil, nil
+	}
+	workspaceID, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		return nil, &requestError{fiber.StatusBadRequest, "Invalid Workspace ID"}
+	}
+	var workspace models.Workspace
+	if admin {
+		err := database.GetCollection("workspace").FindOne(c.Context(), bson.M{"_id": workspaceID}).Decode(&workspace)
+		if err == mongo.ErrNoDocuments {
+			return nil, &requestError{fiber.StatusNotFound, "Workspace not found"}
+		}
+		if err != nil {
+			return nil, &requestError{fiber.StatusInternalServerError, "Could not fetch workspace"}
+		}
+		return &workspaceID, nil
+	}
+	workspace, err = findMemberWorkspace(c.Context(), workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace.OwnerID != userID {
+		return nil, &requestError{fiber.StatusForbidden, "Only the workspace owner can do this"}
+	}
+	return &workspaceID, nil
+}
+
+func isAdmin(c *fiber.Ctx, userID primitive.ObjectID) (bool, error) {
+	var user models.UserRequest
+	if err := database.GetCollection("user").FindOne(c.Context(), bson.M{"_id": userID}).Decode(&user); err != nil {
+		return false, &requestError{fiber.StatusInternalServerError, "Could not fetch user"}
+	}
+	for _, email := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if email = strings.TrimSpace(email); email != "" && strings.EqualFold(email, user.Email) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetConfig returns the config AI calls use for the global scope or workspace_id,
+// with the versions it is built from
+func (h *AIConfigHandler) GetConfig(c *fiber.Ctx) error {
+	workspaceID, err := aiAdminScope(c, c.Query("workspace_id"))
+	if err != nil {
+		return sendError(c, err)
+	}
+	globalVersion, err := aiconfig.ActiveVersion(c.Context(), nil)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch AI config"})
+	}
+	response := fiber.Map{
+		"config":         aiconfig.Resolve(aiconfig.WithScope(c.Context(), nil, workspaceID)),
+		"global_version": globalVersion,
+	}
+	if workspaceID != nil {
+		workspaceVersion, err := aiconfig.ActiveVersion(c.Context(), workspaceID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch AI config"})
+		}
+		response["workspace_version"] = workspaceVersion
+	}
+	return c.JSON(response)
+}
+
+// GetVersions lists the saved versions, newest first
+func (h *AIConfigHandler) GetVersions(c *fiber.Ctx) error {
+	workspaceID, err := aiAdminScope(c, c.Query("workspace_id"))
+	if err != nil {
+		return sendError(c, err)
+	}
+	versions, err := aiconfig.Versions(c.Context(), workspaceID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch AI config versions"})
+	}
+	return c.JSON(versions)
+}
+
+// CreateVersion saves a new version and applies it. The config only needs the
+// entries it overrides.
+func (h *AIConfigHandler) CreateVersion(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	var input models.AIConfigVersionRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	workspace := ""
+	if input.WorkspaceID != nil {
+		workspace = input.WorkspaceID.Hex()
+	}
+	workspaceID, err := aiAdminScope(c, workspace)
+	if err != nil {
+		return sendError(c, err)
+	}
+	// Owners could otherwise lift their own workspace's limits
+	if workspaceID != nil && (len(input.Config.Costs) > 0 || input.Config.Quotas != (models.AIQuotas{})) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Costs and quotas can only be set in the global config"})
+	}
+	if err := aiconfig.Validate(input.Config); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	version, err := aiconfig.Save(c.Context(), workspaceID, input.Config, input.Note, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not save AI config"})
+	}
+	return c.Status(fiber.StatusCreated).JSON(version)
+}
+
+// ActivateVersion applies an earlier version, e.g. to roll back a bad prompt.
+// Version 0 goes back to the file and environment config.
+func (h *AIConfigHandler) ActivateVersion(c *fiber.Ctx) error {
+	workspaceID, err := aiAdminScope(c, c.Query("workspace_id"))
+	if err != nil {
+		return sendError(c, err)
+	}
+	version, err := c.ParamsInt("version")
+	if err != nil || version < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid version"})
+	}
+	if err := aiconfig.Activate(c.Context(), workspaceID, version); err != nil {
+		if err == aiconfig.ErrVersionNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Version not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not activate AI config"})
+	}
+	return c.JSON(fiber.Map{"message": "AI config activated", "version": version})
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/attachment"
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/genai"
+	"github.com/Atif-27/ai-task-manager/importer"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// maxExtractChars is the longest text document accepted, in characters
+	maxExtractChars = 50000
+	// maxPromptPeople caps the user names sent to the model as owner hints
+	maxPromptPeople = 50
+)
+
+// extractTypes are the documents the model reads directly rather than as text
+var extractTypes = map[string]bool{
+	"application/pdf": true,
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/webp":      true,
+}
+
+type extractRequest struct {
+	Text        string `json:"text"`
+	WorkspaceID string `json:"workspace_id"`
+}
+
+// ExtractedDraft is an action item in the shape POST /tasks/import accepts as
+// JSON, plus what the assistant based it on. AssignedTo holds the owner's email
+// when OwnerGuess matched exactly one user; otherwise OwnerCandidates lists the
+// users it could be, if any.
+type ExtractedDraft struct {
+	Title           string               `json:"title"`
+	Description     string               `json:"description"`
+	Status          string               `json:"status"`
+	Priority        string               `json:"priority"`
+	AssignedTo      []string             `json:"assigned_to"`
+	DueDate         string               `json:"due_date,omitempty"`
+	Labels          []string             `json:"labels"`
+	OwnerGuess      string               `json:"owner_guess,omitempty"`
+	OwnerCandidates []models.UserRequest `json:"owner_candidates,omitempty"`
+	SourceQuote     string               `json:"source_quote,omitempty"`
+}
+
+// ExtractTasks lists the action items in a document as draft tasks. Nothing is
+// created: the client reviews the drafts and sends the ones it keeps to POST
+// /tasks/import as a JSON array. The document is either
+//   - JSON {"text": ..., "workspace_id": ...} with pasted text or markdown, or
+//   - an uploaded text, markdown, PDF or image file ("file" form field or the raw
+//     body), with workspace_id as a form or query param.
+//
+// With a workspace, owners are only matched to its members.
+func (h *AIHandler) ExtractTasks(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+
+	var doc genai.Document
+	var workspace string
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+		var input extractRequest
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+		}
+		doc.Text, workspace = input.Text, input.WorkspaceID
+	} else {
+		data, filename, err := readUpload(c)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Could not read upload"})
+		}
+		if len(data) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No document provided"})
+		}
+		// Types DetectType refuses come back empty and are answered below
+		contentType, _ := attachment.DetectType(filename, data)
+		switch {
+		case strings.HasPrefix(contentType, "text/") || contentType == "application/json":
+			if !utf8.Valid(data) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Text documents must be UTF-8"})
+			}
+			doc.Text = string(data)
+		case extractTypes[contentType]:
+			doc.Data, doc.MIMEType = data, contentType
+		}
+		if doc.Text == "" && doc.Data == nil {
+			return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{"error": "Send text, markdown, a PDF or an image"})
+		}
+		workspace = formValue(c, "workspace_id")
+	}
+	if doc.Data == nil {
+		doc.Text = strings.TrimSpace(doc.Text)
+		if doc.Text == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "text is required"})
+		}
+		if len([]rune(doc.Text)) > maxExtractChars {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": "The document is too long"})
+		}
+	}
+
+	var workspaceID *primitive.ObjectID
+	filter := bson.M{}
+	if workspace != "" {
+		id, err := primitive.ObjectIDFromHex(workspace)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Workspace ID"})
+		}
+		found, err := findMemberWorkspace(c.Context(), id, userID)
+		if err != nil {
+			return sendError(c, err)
+		}
+		workspaceID = &id
+		filter["_id"] = bson.M{"$in": found.Members}
+	}
+	cursor, err := database.GetCollection("user").Find(c.Context(), filter, options.Find().SetProjection(bson.M{"name": 1, "email": 1}))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch users"})
+	}
+	var users []models.UserRequest
+	if err := cursor.All(c.Context(), &users); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to parse users"})
+	}
+	var people []string
+	if len(users) <= maxPromptPeople {
+		for _, user := range users {
+			people = append(people, user.Name)
+		}
+	}
+
+	items, err := genai.ExtractTasks(aiconfig.WithScope(c.Context(), &userID, workspaceID), doc, people)
+	if err != nil {
+		log.Printf("Task extraction failed for user %s: %v", userID.Hex(), err)
+		return sendAIError(c, err, "Could not extract tasks")
+	}
+
+	drafts := make([]ExtractedDraft, 0, len(items))
+	for _, item := range items {
+		if strings.TrimSpace(item.Title) == "" {
+			continue
+		}
+		drafts = append(drafts, draftFromItem(item, users))
+	}
+	return c.JSON(fiber.Map{"count": len(drafts), "drafts": drafts})
+}
+
+// draftFromItem turns an extracted item into a draft, matching its owner to
+// users like @handles in quick add and dropping guesses the import would reject
+func draftFromItem(item models.ExtractedItem, users []models.UserRequest) ExtractedDraft {
+	draft := ExtractedDraft{
+		Title:       strings.TrimSpace(item.Title),
+		Description: strings.TrimSpace(item.Description),
+		Status:      string(models.PENDING),
+		Priority:    strings.ToLower(item.Priority),
+		AssignedTo:  []string{},
+		Labels:      []string{},
+		OwnerGuess:  strings.TrimSpace(item.Owner),
+		SourceQuote: strings.TrimSpace(item.Quote),
+	}
+	if !models.PriorityType(draft.Priority).ValidatePriority() {
+		draft.Priority = string(models.MEDIUM)
+	}
+	if _, err := importer.ParseDate(item.DueDate); err == nil {
+		draft.DueDate = item.DueDate
+	}
+
+	handle := strings.TrimPrefix(draft.OwnerGuess, "@")
+	if handle == "" {
+		return draft
+	}
+	var matches []models.UserRequest
+	for _, user := range users {
+		if handleMatches(handle, user) {
+			matches = append(matches, user)
+		}
+	}
+	if len(matches) == 1 {
+		draft.AssignedTo = []string{matches[0].Email}
+	} else {
+		draft.OwnerCandidates = matches
+	}
+	return draft
+}
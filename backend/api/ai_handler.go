@@ -0,0 +1,253 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/breakdown"
+	"github.com/Atif-27/ai-task-manager/genai"
+	"github.com/Atif-27/ai-task-manager/guard"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/query"
+	"github.com/Atif-27/ai-task-manager/recommend"
+	"github.com/Atif-27/ai-task-manager/speech"
+	"github.com/Atif-27/ai-task-manager/summary"
+	"github.com/Atif-27/ai-task-manager/usage"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AIHandler struct{}
+
+// Constructor function for AIHandler
+func MakeAIHandler() *AIHandler {
+	return &AIHandler{}
+}
+
+// sendAIError answers a used-up quota with 429 and its message, a message the
+// guardrails refused with 400, and any other failure of the model with 502 and
+// message
+func sendAIError(c *fiber.Ctx, err error, message string) error {
+	var quota *usage.QuotaError
+	if errors.As(err, &quota) {
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": quota.Message})
+	}
+	var refused *guard.Error
+	if errors.As(err, &refused) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": refused.Message})
+	}
+	return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": message})
+}
+
+// Summary writes a standup (done / doing / blockers / risks) from recent task
+// activity. Query params: scope=me|team (default me), period=day|week (default
+// day), workspace_id to limit team scope to one workspace.
+func (h *AIHandler) Summary(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	req := summary.Request{
+		UserID: userID,
+		Scope:  c.Query("scope", models.SummaryScopeMe),
+		Period: c.Query("period", models.SummaryPeriodDay),
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	workspaceID, err := optionalWorkspace(c, c.Query("workspace_id"), userID)
+	if err != nil {
+		return sendError(c, err)
+	}
+	req.WorkspaceID = workspaceID
+
+	standup, activity, err := summary.Generate(c.Context(), req)
+	if err != nil {
+		log.Printf("Summary failed for user %s: %v", userID.Hex(), err)
+		return sendAIError(c, err, "Could not generate summary")
+	}
+	return c.JSON(fiber.Map{
+		"scope":   req.Scope,
+		"period":  req.Period,
+		"since":   activity.Since,
+		"standup": standup,
+		"counts": fiber.Map{
+			"completed":   len(activity.Completed),
+			"in_progress": len(activity.InProgress),
+			"blocked":     len(activity.Blocked),
+			"overdue":     len(activity.Overdue),
+			"created":     len(activity.Created),
+		},
+	})
+}
+
+type assignSuggestionRequest struct {
+	recommend.Request
+	WorkspaceID *primitive.ObjectID `json:"workspace_id,omitempty"`
+}
+
+// AssignSuggestions ranks who should take a task, from the workspace members when
+// workspace_id is given, the listed candidates, or otherwise every user
+func (h *AIHandler) AssignSuggestions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	var input assignSuggestionRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if strings.TrimSpace(input.Title) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "title is required"})
+	}
+	if input.WorkspaceID != nil {
+		workspace, err := findMemberWorkspace(c.Context(), *input.WorkspaceID, userID)
+		if err != nil {
+			return sendError(c, err)
+		}
+		input.Candidates = workspace.Members
+	}
+	if input.Limit <= 0 {
+		input.Limit = 5
+	}
+
+	candidates, err := recommend.Suggest(c.Context(), input.Request)
+	if err != nil {
+		log.Printf("Assignment suggestions failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not suggest assignees"})
+	}
+	return c.JSON(fiber.Map{"candidates": candidates})
+}
+
+type breakdownRequest struct {
+	Goal string `json:"goal"`
+}
+
+// Breakdown drafts a tree of tasks for a goal. Nothing is created; the client can
+// edit the draft and send it to CommitBreakdown.
+func (h *AIHandler) Breakdown(c *fiber.Ctx) error {
+	var input breakdownRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	input.Goal = strings.TrimSpace(input.Goal)
+	if input.Goal == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "goal is required"})
+	}
+
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	draft, err := genai.GenerateBreakdown(aiconfig.WithScope(c.Context(), &userID, nil), input.Goal)
+	if err != nil {
+		log.Printf("Breakdown failed for %q: %v", input.Goal, err)
+		return sendAIError(c, err, "Could not generate breakdown")
+	}
+	if err := breakdown.Validate(&draft); err != nil {
+		log.Printf("Breakdown for %q was invalid: %v", input.Goal, err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "The generated breakdown was invalid, try again"})
+	}
+	return c.JSON(fiber.Map{"draft": draft})
+}
+
+type commitBreakdownRequest struct {
+	Draft       models.Breakdown     `json:"draft"`
+	AssignedTo  []primitive.ObjectID `json:"assigned_to"`
+	WorkspaceID *primitive.ObjectID  `json:"workspace_id,omitempty"`
+}
+
+// CommitBreakdown creates every task of a (possibly edited) draft as parent and
+// child tasks, all or nothing
+func (h *AIHandler) CommitBreakdown(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	var input commitBreakdownRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if err := breakdown.Validate(&input.Draft); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if input.WorkspaceID != nil {
+		if _, err := findMemberWorkspace(c.Context(), *input.WorkspaceID, userID); err != nil {
+			return sendError(c, err)
+		}
+	}
+
+	tasks, ids := breakdown.ToTasks(input.Draft, userID, input.AssignedTo, input.WorkspaceID)
+	if err := breakdown.Commit(c.Context(), tasks); err != nil {
+		log.Printf("Committing breakdown failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create tasks"})
+	}
+	for _, task := range tasks {
+		AnnounceTaskCreated(task, userID)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Tasks created", "task_ids": ids, "tasks": tasks})
+}
+
+type queryRequest struct {
+	Question string            `json:"question"`
+	Filter   *models.TaskQuery `json:"filter,omitempty"`
+}
+
+// Query answers a question about tasks by translating it into a filter and
+// running it. A filter can be sent instead of a question, e.g. to adjust the one
+// returned by a previous call.
+func (h *AIHandler) Query(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	var input queryRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	input.Question = strings.TrimSpace(input.Question)
+	if input.Question == "" && input.Filter == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "question or filter is required"})
+	}
+
+	var filter models.TaskQuery
+	var tasks []models.Task
+	var err error
+	if input.Filter != nil {
+		filter = *input.Filter
+		tasks, err = query.Run(c.Context(), userID, &filter, time.Now())
+	} else {
+		filter, tasks, err = genai.QueryTasks(aiconfig.WithScope(c.Context(), &userID, nil), userID, input.Question)
+	}
+	if err != nil {
+		var invalid query.Error
+		if errors.As(err, &invalid) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": invalid.Message, "filter": filter})
+		}
+		log.Printf("Query failed for user %s: %v", userID.Hex(), err)
+		if input.Filter != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch tasks"})
+		}
+		return sendAIError(c, err, "Could not answer the question")
+	}
+	return c.JSON(fiber.Map{"filter": filter, "count": len(tasks), "tasks": tasks})
+}
+
+// Voice answers a dictated message: the recording (webm/opus, ogg/opus or wav)
+// is sent as the "file" form field or as the raw body, transcribed, and passed
+// to the chat assistant like a typed message
+func (h *AIHandler) Voice(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	audio, _, err := readUpload(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Could not read the recording"})
+	}
+	if _, err := speech.Detect(audio, c.Get(fiber.HeaderContentType)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx := aiconfig.WithScope(c.Context(), &userID, nil)
+	transcript, err := speech.Transcribe(ctx, audio, "")
+	if errors.Is(err, speech.ErrNoSpeech) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("Transcription failed: %v", err)
+		return sendAIError(c, err, "Could not transcribe the recording")
+	}
+
+	reply, err := genai.ProcessConversation(c.Context(), transcript, userID.Hex())
+	if err != nil {
+		log.Printf("Voice conversation failed: %v", err)
+		return sendAIError(c, err, "The assistant could not answer")
+	}
+	return c.JSON(fiber.Map{"transcript": transcript, "message": reply.Text, "steps": reply.Steps})
+}
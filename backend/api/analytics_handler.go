@@ -0,0 +1,113 @@
+package api
+
+import (
+	"log"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/analytics"
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AnalyticsHandler struct {
+	taskCollection *mongo.Collection
+	userCollection *mongo.Collection
+}
+
+// Constructor function for AnalyticsHandler
+func MakeAnalyticsHandler() *AnalyticsHandler {
+	return &AnalyticsHandler{
+		taskCollection: database.GetCollection("task"),
+		userCollection: database.GetCollection("user"),
+	}
+}
+
+// GetAnalytics returns throughput, cycle time, workload and overdue counts.
+// Query params: from, to (RFC 3339 or YYYY-MM-DD, default the last 30 days),
+// interval=day|week, workspace_id, engine=memory to compute without the
+// aggregation pipelines.
+func (h *AnalyticsHandler) GetAnalytics(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	opts := analytics.Options{Now: time.Now(), Interval: c.Query("interval")}
+	var err error
+	if value := c.Query("from"); value != "" {
+		if opts.From, err = parseReportDate(value); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid from date"})
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if opts.To, err = parseReportDate(value); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid to date"})
+		}
+	}
+	if opts.WorkspaceID, err = optionalWorkspace(c, c.Query("workspace_id"), userID); err != nil {
+		return sendError(c, err)
+	}
+	if err := opts.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var report analytics.Report
+	if c.Query("engine") == "memory" {
+		report, err = h.computeInMemory(c, opts)
+	} else {
+		report, err = analytics.Aggregate(c.Context(), opts)
+	}
+	if err != nil {
+		log.Printf("Analytics failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not compute analytics"})
+	}
+
+	if err := h.nameAssignees(c, report.Workload); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch users"})
+	}
+	return c.JSON(report)
+}
+
+func (h *AnalyticsHandler) computeInMemory(c *fiber.Ctx, opts analytics.Options) (analytics.Report, error) {
+	filter := trash.Live(bson.M{})
+	if opts.WorkspaceID != nil {
+		filter["workspace_id"] = *opts.WorkspaceID
+	}
+	cursor, err := h.taskCollection.Find(c.Context(), filter)
+	if err != nil {
+		return analytics.Report{}, err
+	}
+	var tasks []models.Task
+	if err := cursor.All(c.Context(), &tasks); err != nil {
+		return analytics.Report{}, err
+	}
+	return analytics.Compute(tasks, opts)
+}
+
+func (h *AnalyticsHandler) nameAssignees(c *fiber.Ctx, workload []analytics.Workload) error {
+	if len(workload) == 0 {
+		return nil
+	}
+	ids := make([]primitive.ObjectID, len(workload))
+	for i, load := range workload {
+		ids[i] = load.UserID
+	}
+	cursor, err := h.userCollection.Find(c.Context(), bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"name": 1}))
+	if err != nil {
+		return err
+	}
+	var users []models.UserRequest
+	if err := cursor.All(c.Context(), &users); err != nil {
+		return err
+	}
+	names := make(map[primitive.ObjectID]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.Name
+	}
+	for i := range workload {
+		workload[i].Name = names[workload[i].UserID]
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/Atif-27/ai-task-manager/attachment"
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"github.com/Atif-27/ai-task-manager/ws"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AttachmentHandler struct {
+	taskCollection       *mongo.Collection
+	attachmentCollection *mongo.Collection
+}
+
+// Constructor function for AttachmentHandler
+func MakeAttachmentHandler() *AttachmentHandler {
+	return &AttachmentHandler{
+		taskCollection:       database.GetCollection("task"),
+		attachmentCollection: database.GetCollection("attachment"),
+	}
+}
+
+// taskParam parses :id and checks the task exists
+func (h *AttachmentHandler) taskParam(c *fiber.Ctx) (primitive.ObjectID, error) {
+	taskID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return taskID, &requestError{fiber.StatusBadRequest, "Invalid Task ID"}
+	}
+	count, err := h.taskCollection.CountDocuments(c.Context(), trash.Live(bson.M{"_id": taskID}))
+	if err != nil {
+		return taskID, &requestError{fiber.StatusInternalServerError, "Could not fetch task"}
+	}
+	if count == 0 {
+		return taskID, &requestError{fiber.StatusNotFound, "Task not found"}
+	}
+	return taskID, nil
+}
+
+// attachmentParam finds :attachmentId on the task :id, which must not be trashed
+func (h *AttachmentHandler) attachmentParam(c *fiber.Ctx) (models.Attachment, error) {
+	var found models.Attachment
+	taskID, err := h.taskParam(c)
+	if err != nil {
+		return found, err
+	}
+	attachmentID, err := primitive.ObjectIDFromHex(c.Params("attachmentId"))
+	if err != nil {
+		return found, &requestError{fiber.StatusBadRequest, "Invalid attachment ID"}
+	}
+	err = h.attachmentCollection.FindOne(c.Context(), bson.M{"_id": attachmentID, "task_id": taskID}).Decode(&found)
+	if err == mongo.ErrNoDocuments {
+		return found, &requestError{fiber.StatusNotFound, "Attachment not found"}
+	}
+	if err != nil {
+		return found, &requestError{fiber.StatusInternalServerError, "Could not fetch attachment"}
+	}
+	return found, nil
+}
+
+// Upload attaches the multipart "file" field to a task
+func (h *AttachmentHandler) Upload(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	taskID, err := h.taskParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Send the file as the multipart field \"file\""})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Could not read the file"})
+	}
+	defer file.Close()
+
+	saved, err := attachment.Save(c.Context(), taskID, userID, fileHeader.Filename, file, fileHeader.Size)
+	if errors.Is(err, attachment.ErrTooLarge) {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": fmt.Sprintf("Files can be at most %d MB", attachment.MaxBytes()>>20)})
+	}
+	if errors.Is(err, attachment.ErrNotAllowed) {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("Attachment upload failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not save attachment"})
+	}
+
+	go ws.WSManager.Broadcast(fiber.Map{"event": "attachment_added", "task_id": taskID, "attachment": saved})
+	return c.Status(fiber.StatusCreated).JSON(saved)
+}
+
+// GetAttachments lists a task's attachments
+func (h *AttachmentHandler) GetAttachments(c *fiber.Ctx) error {
+	taskID, err := h.taskParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+	attachments, err := attachment.List(c.Context(), taskID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch attachments"})
+	}
+	return c.JSON(attachments)
+}
+
+// Download streams an attachment, honouring a single-range Range header
+func (h *AttachmentHandler) Download(c *fiber.Ctx) error {
+	found, err := h.attachmentParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	offset, length, partial, ok := parseRange(c.Get(fiber.HeaderRange), found.Size)
+	if !ok {
+		c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", found.Size))
+		return c.Status(fiber.StatusRequestedRangeNotSatisfiable).JSON(fiber.Map{"error": "Invalid range"})
+	}
+	reader, err := attachment.Open(c.Context(), found, offset, length)
+	if err != nil {
+		log.Printf("Attachment download failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not read attachment"})
+	}
+
+	c.Set(fiber.HeaderContentType, found.ContentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": found.Filename}))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	if partial {
+		c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, found.Size))
+		c.Status(fiber.StatusPartialContent)
+	}
+	// Fiber closes the reader once the response is written
+	return c.SendStream(reader, int(length))
+}
+
+// parseRange reads a "bytes=" Range header for a file of size bytes. Without a
+// header, or with several ranges, the whole file is sent. ok is false when the
+// range can't be satisfied.
+func parseRange(header string, size int64) (offset, length int64, partial bool, ok bool) {
+	if header == "" || !strings.HasPrefix(header, "bytes=") || strings.Contains(header, ",") {
+		return 0, size, false, true
+	}
+	spec := strings.TrimSpace(strings.TrimPrefix(header, "bytes="))
+	startText, endText, found := strings.Cut(spec, "-")
+	if !found {
+		return 0, 0, false, false
+	}
+	if startText == "" {
+		// bytes=-N is the last N bytes
+		suffix, err := strconv.ParseInt(endText, 10, 64)
+		if err != nil || suffix <= 0 {
+			return 0, 0, false, false
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return size - suffix, suffix, true, size > 0
+	}
+	start, err := strconv.ParseInt(startText, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false, false
+	}
+	end := size - 1
+	if endText != "" {
+		if end, err = strconv.ParseInt(endText, 10, 64); err != nil || end < start {
+			return 0, 0, false, false
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+	return start, end - start + 1, true, true
+}
+
+// DeleteAttachment removes an attachment. Only the user who uploaded it can.
+func (h *AttachmentHandler) DeleteAttachment(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	found, err := h.attachmentParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+	if found.UploadedBy != userID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only the uploader can delete an attachment"})
+	}
+	if err := attachment.Delete(c.Context(), found); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete attachment"})
+	}
+	go ws.WSManager.Broadcast(fiber.Map{"event": "attachment_deleted", "task_id": found.TaskID, "attachment_id": found.ID})
+	return c.JSON(fiber.Map{"message": "Attachment deleted", "attachment_id": found.ID})
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"io"
+	"regexp"
+
+	"github.com/Atif-27/ai-task-manager/attachment"
+	"github.com/gofiber/fiber/v2"
+)
+
+// attachmentUpload matches the path of POST /tasks/:id/attachments
+var attachmentUpload = regexp.MustCompile(`/tasks/[^/]+/attachments/?$`)
+
+// LimitBody caps request bodies at fiber's default size, except attachment
+// uploads, which may be as large as the attachment limit plus room for the
+// multipart overhead. The server streams request bodies so that uploads can
+// get past its own limit; this middleware is what enforces the sizes.
+func LimitBody(c *fiber.Ctx) error {
+	limit := fiber.DefaultBodyLimit
+	if c.Method() == fiber.MethodPost && attachmentUpload.MatchString(c.Path()) {
+		limit = int(attachment.MaxBytes()) + 1<<20
+	}
+
+	req := c.Request()
+	if req.Header.ContentLength() > limit {
+		return tooLarge(c)
+	}
+	if req.IsBodyStream() {
+		// Chunked bodies have no length up front, so read at most one byte past the limit
+		body, err := io.ReadAll(io.LimitReader(req.BodyStream(), int64(limit)+1))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Could not read request body"})
+		}
+		if len(body) > limit {
+			return tooLarge(c)
+		}
+		req.SetBody(body)
+	}
+	return c.Next()
+}
+
+// tooLarge refuses a body without reading the rest of it, so the connection
+// can't be reused
+func tooLarge(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderConnection, "close")
+	return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": "Request body too large"})
+}
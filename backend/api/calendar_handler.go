@@ -0,0 +1,129 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/Atif-27/ai-task-manager/calendar"
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CalendarHandler struct {
+	userCollection *mongo.Collection
+	taskCollection *mongo.Collection
+}
+
+// Constructor function for CalendarHandler
+func MakeCalendarHandler() *CalendarHandler {
+	return &CalendarHandler{
+		userCollection: database.GetCollection("user"),
+		taskCollection: database.GetCollection("task"),
+	}
+}
+
+func calendarURL(token string) string {
+	return "/api/v1/calendar/" + token + ".ics"
+}
+
+func newCalendarToken() (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// GetCalendar returns the caller's feed URL, creating the token on first use
+func (h *CalendarHandler) GetCalendar(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	var user models.User
+	if err := h.userCollection.FindOne(c.Context(), bson.M{"_id": userID}).Decode(&user); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	}
+	if user.CalendarToken != "" {
+		return c.JSON(fiber.Map{"url": calendarURL(user.CalendarToken)})
+	}
+	return h.setToken(c, userID, bson.M{"_id": userID, "calendar_token": bson.M{"$exists": false}})
+}
+
+// RotateCalendar replaces the caller's feed token; the old URL stops working
+func (h *CalendarHandler) RotateCalendar(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	return h.setToken(c, userID, bson.M{"_id": userID})
+}
+
+func (h *CalendarHandler) setToken(c *fiber.Ctx, userID primitive.ObjectID, filter bson.M) error {
+	token, err := newCalendarToken()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not generate token"})
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var user models.User
+	err = h.userCollection.FindOneAndUpdate(c.Context(), filter, bson.M{"$set": bson.M{"calendar_token": token}}, opts).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		// A concurrent request created the token first
+		if err := h.userCollection.FindOne(c.Context(), bson.M{"_id": userID}).Decode(&user); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		}
+	} else if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update calendar token"})
+	}
+	return c.JSON(fiber.Map{"url": calendarURL(user.CalendarToken)})
+}
+
+// Feed serves the tasks assigned to the token's owner that have a due date. The
+// token in the URL is the credential. Query param type=todo renders VTODO
+// components instead of VEVENT.
+func (h *CalendarHandler) Feed(c *fiber.Ctx) error {
+	token := c.Params("token")
+	if token == "" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Unknown calendar"})
+	}
+	var user models.User
+	if err := h.userCollection.FindOne(c.Context(), bson.M{"calendar_token": token}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Unknown calendar"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch calendar"})
+	}
+
+	component := calendar.ComponentEvent
+	if c.Query("type") == "todo" {
+		component = calendar.ComponentTodo
+	}
+
+	filter := trash.Live(bson.M{"assigned_to": user.ID, "due_date": bson.M{"$ne": nil}})
+	cursor, err := h.taskCollection.Find(c.Context(), filter, options.Find().SetSort(bson.M{"due_date": 1}))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch tasks"})
+	}
+	var tasks []models.Task
+	if err := cursor.All(c.Context(), &tasks); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not decode tasks"})
+	}
+
+	body := calendar.Render(user.Name+" - Tasks", component, tasks)
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=300")
+	for _, match := range strings.Split(c.Get(fiber.HeaderIfNoneMatch), ",") {
+		match = strings.TrimPrefix(strings.TrimSpace(match), "W/")
+		if match == etag || match == "*" {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `inline; filename="tasks.ics"`)
+	return c.Send(body)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/Atif-27/ai-task-manager/guard"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+type GuardHandler struct{}
+
+// Constructor function for GuardHandler
+func MakeGuardHandler() *GuardHandler {
+	return &GuardHandler{}
+}
+
+// GetEvents lists what the AI guardrails blocked, newest first, for admins to
+// review. Query params: kind=input|tool_args|tool_access|injection, limit (default 50, max 500).
+func (h *GuardHandler) GetEvents(c *fiber.Ctx) error {
+	if _, err := aiAdminScope(c, ""); err != nil {
+		return sendError(c, err)
+	}
+	kind := c.Query("kind")
+	switch kind {
+	case "", models.GuardInput, models.GuardToolArgs, models.GuardToolAccess, models.GuardInjection:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "kind must be input, tool_args, tool_access or injection"})
+	}
+	limit, err := strconv.ParseInt(c.Query("limit", "50"), 10, 64)
+	if err != nil || limit < 1 || limit > 500 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "limit must be between 1 and 500"})
+	}
+
+	events, err := guard.Events(c.Context(), kind, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch guard events"})
+	}
+	return c.JSON(fiber.Map{"events": events})
+}
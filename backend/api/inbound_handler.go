@@ -0,0 +1,203 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/attachment"
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/genai"
+	"github.com/Atif-27/ai-task-manager/inbound"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/ws"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type InboundHandler struct {
+	tokenCollection *mongo.Collection
+	tasks           *TaskHandler
+}
+
+// Constructor function for InboundHandler
+func MakeInboundHandler(taskHandler *TaskHandler) *InboundHandler {
+	return &InboundHandler{
+		tokenCollection: database.GetCollection("inbound_token"),
+		tasks:           taskHandler,
+	}
+}
+
+func (h *InboundHandler) CreateToken(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	workspaceID, err := workspaceParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+	var input models.CreateInboundTokenRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	for _, to := range input.Template.PriorityMap {
+		if !models.PriorityType(strings.ToLower(to)).ValidatePriority() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "priority_map values must be low, medium or high"})
+		}
+	}
+	if input.AssignTo == nil {
+		input.AssignTo = []primitive.ObjectID{}
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not generate token"})
+	}
+	token := models.InboundToken{
+		ID:          primitive.NewObjectID(),
+		WorkspaceID: workspaceID,
+		Name:        input.Name,
+		Token:       hex.EncodeToString(secret),
+		Template:    input.Template,
+		AssignTo:    input.AssignTo,
+		UseAI:       input.UseAI,
+		CreatedBy:   userID,
+		CreatedAt:   time.Now(),
+	}
+	if _, err := h.tokenCollection.InsertOne(c.Context(), token); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create token"})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Inbound token created",
+		"token":   token,
+		"url":     "/api/v1/inbound/" + token.Token,
+	})
+}
+
+func (h *InboundHandler) GetTokens(c *fiber.Ctx) error {
+	workspaceID, err := workspaceParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+	cursor, err := h.tokenCollection.Find(c.Context(), bson.M{"workspace_id": workspaceID})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch tokens"})
+	}
+	tokens := []models.InboundToken{}
+	if err := cursor.All(c.Context(), &tokens); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to parse tokens"})
+	}
+	return c.JSON(fiber.Map{"tokens": tokens})
+}
+
+func (h *InboundHandler) DeleteToken(c *fiber.Ctx) error {
+	workspaceID, err := workspaceParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+	tokenID, err := primitive.ObjectIDFromHex(c.Params("tokenId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Token ID"})
+	}
+	result, err := h.tokenCollection.DeleteOne(c.Context(), bson.M{"_id": tokenID, "workspace_id": workspaceID})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete token"})
+	}
+	if result.DeletedCount == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Token not found"})
+	}
+	return c.JSON(fiber.Map{"message": "Inbound token deleted", "token_id": tokenID})
+}
+
+// Receive creates a task from an unauthenticated inbound request; the token in the
+// URL is the credential. JSON bodies go through the token's template, while
+// message/rfc822 (or text/plain) bodies are parsed as a raw email, whose
+// attachments are stored on the task.
+func (h *InboundHandler) Receive(c *fiber.Ctx) error {
+	var token models.InboundToken
+	err := h.tokenCollection.FindOne(c.Context(), bson.M{"token": c.Params("token")}).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Unknown inbound token"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch token"})
+	}
+
+	var draft inbound.Draft
+	contentType := strings.ToLower(c.Get(fiber.HeaderContentType))
+	if strings.HasPrefix(contentType, "message/rfc822") || strings.HasPrefix(contentType, "text/plain") {
+		draft, err = inbound.FromEmail(c.Body())
+	} else {
+		draft, err = inbound.FromJSON(c.Body(), token.Template)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if draft.Title == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Could not determine a task title from the payload"})
+	}
+
+	var estimate *int
+	if token.UseAI && (draft.Priority == "" || draft.Description == "") {
+		ctx := aiconfig.WithScope(c.Context(), nil, &token.WorkspaceID)
+		suggestion, err := genai.GetAISuggestion(ctx, draft.Title)
+		if err != nil {
+			log.Printf("Inbound: AI suggestion failed for %q: %v", draft.Title, err)
+		} else {
+			if draft.Priority == "" {
+				draft.Priority = inbound.MapPriority(suggestion.Priority, nil)
+			}
+			if draft.Description == "" {
+				draft.Description = strings.Join(suggestion.Description, "\n")
+			}
+			if suggestion.EstimateMinutes > 0 {
+				estimate = &suggestion.EstimateMinutes
+			}
+		}
+	}
+
+	workspaceID := token.WorkspaceID
+	task := models.Task{
+		Title:       draft.Title,
+		Description: draft.Description,
+		Priority:    draft.Priority,
+		AssignedTo:  token.AssignTo,
+		WorkspaceID: &workspaceID,
+
+		EstimateMinutes: estimate,
+	}
+	if err := h.tasks.insertTask(c.Context(), &task, token.CreatedBy); err != nil {
+		return sendError(c, err)
+	}
+	saved, skipped := saveInboundAttachments(c.Context(), task.ID, token.CreatedBy, draft.Attachments)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Task created", "task": task, "attachments": saved, "skipped_attachments": skipped})
+}
+
+// saveInboundAttachments stores an email's attachments on the task created from
+// it, as uploaded by the token's owner. Files the store refuses, e.g. too large
+// or of a type that isn't allowed, are skipped with the reason.
+func saveInboundAttachments(ctx context.Context, taskID, userID primitive.ObjectID, files []inbound.Attachment) ([]models.Attachment, []fiber.Map) {
+	saved := []models.Attachment{}
+	skipped := []fiber.Map{}
+	for _, file := range files {
+		stored, err := attachment.Save(ctx, taskID, userID, file.Filename, bytes.NewReader(file.Data), int64(len(file.Data)))
+		if err != nil {
+			log.Printf("Inbound: could not save attachment %q of task %s: %v", file.Filename, taskID.Hex(), err)
+			reason := "Could not save attachment"
+			if errors.Is(err, attachment.ErrTooLarge) || errors.Is(err, attachment.ErrNotAllowed) {
+				reason = err.Error()
+			}
+			skipped = append(skipped, fiber.Map{"filename": file.Filename, "error": reason})
+			continue
+		}
+		saved = append(saved, stored)
+		go ws.WSManager.Broadcast(fiber.Map{"event": "attachment_added", "task_id": taskID, "attachment": stored})
+	}
+	return saved, skipped
+}
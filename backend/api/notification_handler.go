@@ -0,0 +1,105 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationHandler struct {
+	notificationCollection *mongo.Collection
+}
+
+// Constructor function for NotificationHandler
+func MakeNotificationHandler() *NotificationHandler {
+	return &NotificationHandler{
+		notificationCollection: database.GetCollection("notification"),
+	}
+}
+
+// GetNotifications lists the caller's inbox, newest first.
+// Query params: page (default 1), limit (default 20, max 100), unread=true to hide read items.
+func (n *NotificationHandler) GetNotifications(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid page"})
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "limit must be between 1 and 100"})
+	}
+
+	filter := bson.M{"user_id": userID}
+	if c.Query("unread") == "true" {
+		filter["read"] = false
+	}
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := n.notificationCollection.Find(c.Context(), filter, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch notifications"})
+	}
+	notifications := []models.Notification{}
+	if err := cursor.All(c.Context(), &notifications); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to parse notifications"})
+	}
+
+	total, err := n.notificationCollection.CountDocuments(c.Context(), filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not count notifications"})
+	}
+	unread, err := n.notificationCollection.CountDocuments(c.Context(), bson.M{"user_id": userID, "read": false})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not count notifications"})
+	}
+
+	return c.JSON(fiber.Map{
+		"notifications": notifications,
+		"unread_count":  unread,
+		"total":         total,
+		"page":          page,
+		"limit":         limit,
+	})
+}
+
+func (n *NotificationHandler) MarkRead(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid notification ID"})
+	}
+
+	filter := bson.M{"_id": id, "user_id": userID}
+	update := bson.M{"$set": bson.M{"read": true, "read_at": time.Now()}}
+	result, err := n.notificationCollection.UpdateOne(c.Context(), filter, update)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update notification"})
+	}
+	if result.MatchedCount == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Notification not found"})
+	}
+	return c.JSON(fiber.Map{"message": "Notification marked as read", "notification_id": id})
+}
+
+func (n *NotificationHandler) MarkAllRead(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+
+	filter := bson.M{"user_id": userID, "read": false}
+	update := bson.M{"$set": bson.M{"read": true, "read_at": time.Now()}}
+	result, err := n.notificationCollection.UpdateMany(c.Context(), filter, update)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update notifications"})
+	}
+	return c.JSON(fiber.Map{"message": "All notifications marked as read", "updated": result.ModifiedCount})
+}
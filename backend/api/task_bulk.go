@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/activity"
+	"github.com/Atif-27/ai-task-manager/bulk"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/query"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"github.com/Atif-27/ai-task-manager/webhook"
+	"github.com/Atif-27/ai-task-manager/ws"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BulkTasks applies a list of operations (set_status, set_priority,
+// set_assignees, add_label or delete) to up to bulk.MaxTasks tasks, given as
+// task_ids or selected with a filter like the one of POST /ai/query. Only tasks
+// the caller can see are changed; the others are reported as not_found, like
+// tasks trashed while the request runs. The change is announced with a single
+// tasks_bulk_updated event and recorded in the activity log; webhooks still get
+// a task_updated or task_deleted per task.
+func (t *TaskHandler) BulkTasks(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	var input models.BulkRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if (len(input.TaskIDs) == 0) == (input.Filter == nil) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Send either task_ids or filter"})
+	}
+	if len(input.TaskIDs) > bulk.MaxTasks {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("Too many tasks, the limit is %d", bulk.MaxTasks)})
+	}
+	if err := bulk.Validate(input.Operations); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	tasks, missing, err := t.bulkTargets(c, userID, input)
+	if err != nil {
+		var invalid query.Error
+		if errors.As(err, &invalid) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": invalid.Message})
+		}
+		var tooMany bulk.Error
+		if errors.As(err, &tooMany) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": tooMany.Message})
+		}
+		log.Printf("Bulk update could not load tasks: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch tasks"})
+	}
+
+	results, err := bulk.Apply(c.Context(), userID, tasks, input.Operations)
+	if err != nil {
+		log.Printf("Bulk update failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update tasks, nothing was changed"})
+	}
+
+	counts := map[string]int{models.BulkUpdated: 0, models.BulkDeleted: 0, models.BulkFailed: 0, models.BulkNotFound: len(missing)}
+	var changed []primitive.ObjectID
+	var workspaces []primitive.ObjectID
+	seenWorkspace := map[primitive.ObjectID]bool{}
+	for i, result := range results {
+		counts[result.Status]++
+		if result.Status == models.BulkFailed || result.Status == models.BulkNotFound {
+			continue
+		}
+		changed = append(changed, result.TaskID)
+		if workspaceID := tasks[i].WorkspaceID; workspaceID != nil && !seenWorkspace[*workspaceID] {
+			seenWorkspace[*workspaceID] = true
+			workspaces = append(workspaces, *workspaceID)
+		}
+	}
+	for _, id := range missing {
+		results = append(results, models.BulkResult{TaskID: id, Status: models.BulkNotFound})
+	}
+
+	if len(changed) > 0 {
+		deleted := input.Operations[0].Op == models.BulkDelete
+		go ws.WSManager.Broadcast(fiber.Map{
+			"event":      "tasks_bulk_updated",
+			"task_ids":   changed,
+			"operations": input.Operations,
+			"deleted":    deleted,
+			"by":         userID,
+		})
+		go activity.Record(models.Activity{
+			Action:       models.ActivityTasksBulkUpdated,
+			ActorID:      userID,
+			WorkspaceIDs: workspaces,
+			TaskIDs:      changed,
+			Details:      map[string]interface{}{"operations": input.Operations, "counts": counts},
+		})
+		go t.announceBulkChanges(tasks, changed, userID)
+	}
+	return c.JSON(fiber.Map{"results": results, "counts": counts})
+}
+
+// bulkTargets loads the tasks a bulk request names or matches, among those the
+// caller can see, and returns the requested IDs that weren't found. A filter
+// without a limit must not match more than bulk.MaxTasks tasks, rather than
+// changing an arbitrary part of them.
+func (t *TaskHandler) bulkTargets(c *fiber.Ctx, userID primitive.ObjectID, input models.BulkRequest) ([]models.Task, []primitive.ObjectID, error) {
+	if input.Filter != nil {
+		filter := *input.Filter
+		if filter.Limit > bulk.MaxTasks {
+			return nil, nil, bulk.Error{Message: fmt.Sprintf("Too many tasks, the limit is %d", bulk.MaxTasks)}
+		}
+		limited := filter.Limit > 0
+		if !limited {
+			filter.Limit = bulk.MaxTasks
+		}
+		now := time.Now()
+		tasks, err := query.Run(c.Context(), userID, &filter, now)
+		if err != nil || limited || len(tasks) < bulk.MaxTasks {
+			return tasks, nil, err
+		}
+		matched, err := query.Count(c.Context(), userID, &filter, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		if matched > bulk.MaxTasks {
+			return nil, nil, bulk.Error{Message: fmt.Sprintf("The filter matches %d tasks, the limit is %d: narrow it or set a limit", matched, bulk.MaxTasks)}
+		}
+		return tasks, nil, nil
+	}
+
+	visible, err := query.Visible(c.Context(), userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter := trash.Live(bson.M{"_id": bson.M{"$in": input.TaskIDs}, "$and": []bson.M{visible}})
+	cursor, err := t.taskCollection.Find(c.Context(), filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	var found []models.Task
+	if err := cursor.All(c.Context(), &found); err != nil {
+		return nil, nil, err
+	}
+
+	// Keep the requested order and drop duplicates
+	byID := make(map[primitive.ObjectID]models.Task, len(found))
+	for _, task := range found {
+		byID[task.ID] = task
+	}
+	tasks := make([]models.Task, 0, len(found))
+	var missing []primitive.ObjectID
+	seen := map[primitive.ObjectID]bool{}
+	for _, id := range input.TaskIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if task, ok := byID[id]; ok {
+			tasks = append(tasks, task)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return tasks, missing, nil
+}
+
+// announceBulkChanges sends the webhooks and notifications a single update or
+// delete of each changed task would, e.g. to new assignees
+func (t *TaskHandler) announceBulkChanges(before []models.Task, changed []primitive.ObjectID, actorID primitive.ObjectID) {
+	ctx := context.Background()
+	cursor, err := t.taskCollection.Find(ctx, bson.M{"_id": bson.M{"$in": changed}})
+	if err != nil {
+		log.Printf("Could not load bulk updated tasks for notifications: %v", err)
+		return
+	}
+	var after []models.Task
+	if err := cursor.All(ctx, &after); err != nil {
+		log.Printf("Could not load bulk updated tasks for notifications: %v", err)
+		return
+	}
+	previous := make(map[primitive.ObjectID]models.Task, len(before))
+	for _, task := range before {
+		previous[task.ID] = task
+	}
+	for _, task := range after {
+		id := task.ID.Hex()
+		if task.DeletedAt != nil {
+			event := fiber.Map{"event": "task_deleted", "task_id": id, "purge_at": trash.PurgeAt(*task.DeletedAt)}
+			webhook.Dispatch(ctx, task.WorkspaceID, models.WebhookTaskDeleted, event)
+			continue
+		}
+		event := fiber.Map{"event": "task_updated", "task_id": id, "updates": task}
+		webhook.Dispatch(ctx, task.WorkspaceID, models.WebhookTaskUpdated, event)
+		notifyTaskUpdated(previous[task.ID], task, actorID)
+	}
+}
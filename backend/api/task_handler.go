@@ -1,15 +1,21 @@
 package api
 
 import (
+	"context"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Atif-27/ai-task-manager/database"
 	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"github.com/Atif-27/ai-task-manager/webhook"
 	"github.com/Atif-27/ai-task-manager/ws"
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type TaskHandler struct {
@@ -31,11 +37,29 @@ type TaskWithUserDetails struct {
 }
 
 func (t *TaskHandler) CreateTask(c *fiber.Ctx) error {
+	if c.QueryBool("quick") {
+		return t.QuickAdd(c)
+	}
 	userId := c.Locals("user_id").(primitive.ObjectID)
 	var task models.Task
 	if err := c.BodyParser(&task); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
 	}
+	if task.WorkspaceID != nil {
+		if _, err := findMemberWorkspace(c.Context(), *task.WorkspaceID, userId); err != nil {
+			return sendError(c, err)
+		}
+	}
+	if err := t.insertTask(c.Context(), &task, userId); err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Task created", "task": task})
+}
+
+// insertTask fills in defaults, validates and stores a new task, then announces it
+// over the WebSocket, to webhooks and to the users it concerns. Every way of
+// creating a task goes through here.
+func (t *TaskHandler) insertTask(ctx context.Context, task *models.Task, userId primitive.ObjectID) error {
 	if task.Status == "" {
 		task.Status = models.PENDING
 	}
@@ -46,21 +70,44 @@ func (t *TaskHandler) CreateTask(c *fiber.Ctx) error {
 		task.AssignedTo = []primitive.ObjectID{}
 	}
 	if !task.Status.ValidateStatus() || !task.Priority.ValidatePriority() {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Status or priority"})
+		return &requestError{fiber.StatusBadRequest, "Invalid Status or priority"}
 	}
+	if !validEstimate(task.EstimateMinutes, task.StoryPoints) {
+		return &requestError{fiber.StatusBadRequest, "Estimates can't be negative"}
+	}
+	// Time spent only comes from time entries
+	task.TimeSpentSeconds = 0
 	task.CreatedAt = time.Now()
 	task.UpdatedAt = time.Now()
 	task.AssignedBy = userId
+	task.StatusHistory = []models.StatusChange{{Status: task.Status, At: task.CreatedAt, By: userId}}
 	task.ID = primitive.NewObjectID()
-	_, err := t.taskCollection.InsertOne(c.Context(), task)
+	_, err := t.taskCollection.InsertOne(ctx, task)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create task"})
+		return &requestError{fiber.StatusInternalServerError, "Could not create task"}
 	}
-	ws.WSManager.Broadcast(fiber.Map{"event": "task_created", "task": task})
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Task created", "task": task})
+	AnnounceTaskCreated(*task, userId)
+	return nil
+}
+
+// AnnounceTaskCreated tells WebSocket clients, webhooks and the users concerned
+// about a new task. main also hands it to genai for the tasks the chat creates.
+func AnnounceTaskCreated(task models.Task, userId primitive.ObjectID) {
+	event := fiber.Map{"event": "task_created", "task": task}
+	ws.WSManager.Broadcast(event)
+	go webhook.Dispatch(context.Background(), task.WorkspaceID, models.WebhookTaskCreated, event)
+	go notifyTaskCreated(task, userId)
+}
+
+func validEstimate(minutes *int, points *float64) bool {
+	return (minutes == nil || *minutes >= 0) && (points == nil || *points >= 0)
 }
 
+// UpdateTask changes the fields sent in the body. If-Match must carry the ETag
+// (the version) of the task the client edited; when someone changed the task
+// since, nothing is written and 412 returns the current task.
 func (t *TaskHandler) UpdateTask(c *fiber.Ctx) error {
+	userId := c.Locals("user_id").(primitive.ObjectID)
 	id := c.Params("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -87,55 +134,138 @@ func (t *TaskHandler) UpdateTask(c *fiber.Ctx) error {
 	if updateData.AssignedTo != nil {
 		updateFields["assigned_to"] = *updateData.AssignedTo
 	}
+	if updateData.DueDate != nil {
+		updateFields["due_date"] = *updateData.DueDate
+		// A new due date deserves a new reminder
+		updateFields["due_soon_notified"] = false
+	}
+	if !validEstimate(updateData.EstimateMinutes, updateData.StoryPoints) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Estimates can't be negative"})
+	}
+	if updateData.EstimateMinutes != nil {
+		updateFields["estimate_minutes"] = *updateData.EstimateMinutes
+	}
+	if updateData.StoryPoints != nil {
+		updateFields["story_points"] = *updateData.StoryPoints
+	}
 
 	if len(updateFields) == 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No valid fields to update"})
 	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return sendError(c, err)
+	}
 
-	updateFields["updated_at"] = time.Now()
-	filter := bson.M{"_id": objID}
-	update := bson.M{"$set": updateFields}
-	_, err = t.taskCollection.UpdateOne(c.Context(), filter, update)
+	var previousTask models.Task
+	err = t.taskCollection.FindOne(c.Context(), trash.Live(bson.M{"_id": objID})).Decode(&previousTask)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update task"})
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch task"})
+	}
+	if previousTask.Version != version {
+		return sendConflict(c, previousTask)
 	}
 
+	updateFields["updated_at"] = time.Now()
+	filter := trash.Live(bson.M{"_id": objID, "version": versionFilter(version)})
+	update := bson.M{"$set": updateFields, "$inc": bson.M{"version": 1}}
+	if status, ok := updateFields["status"]; ok && status != previousTask.Status {
+		update["$push"] = bson.M{"status_history": models.StatusChange{Status: *updateData.Status, At: time.Now(), By: userId}}
+	}
 	var updatedTask models.Task
-	err = t.taskCollection.FindOne(c.Context(), bson.M{"_id": objID}).Decode(&updatedTask)
+	err = t.taskCollection.FindOneAndUpdate(c.Context(), filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updatedTask)
+	if err == mongo.ErrNoDocuments {
+		// Changed or deleted since it was read
+		var current models.Task
+		if err := t.taskCollection.FindOne(c.Context(), trash.Live(bson.M{"_id": objID})).Decode(&current); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
+		}
+		return sendConflict(c, current)
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch updated task"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update task"})
 	}
 
-	ws.WSManager.Broadcast(fiber.Map{"event": "task_updated", "task_id": id, "updates": updatedTask})
+	event := fiber.Map{"event": "task_updated", "task_id": id, "updates": updatedTask}
+	ws.WSManager.Broadcast(event)
+	go webhook.Dispatch(context.Background(), updatedTask.WorkspaceID, models.WebhookTaskUpdated, event)
+	go notifyTaskUpdated(previousTask, updatedTask, userId)
+	c.Set(fiber.HeaderETag, taskETag(updatedTask))
 	return c.JSON(fiber.Map{"message": "Task updated", "updated_fields": updatedTask})
 }
 
+// taskETag identifies the version of a task, for If-Match on updates
+func taskETag(task models.Task) string {
+	return `"` + strconv.FormatInt(task.Version, 10) + `"`
+}
+
+// ifMatchVersion reads the task version from If-Match. Without it an update
+// could silently overwrite someone else's, so it is required.
+func ifMatchVersion(c *fiber.Ctx) (int64, error) {
+	match := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if match == "" {
+		return 0, &requestError{fiber.StatusPreconditionRequired, "If-Match with the task's ETag is required"}
+	}
+	match = strings.Trim(strings.TrimPrefix(match, "W/"), `"`)
+	version, err := strconv.ParseInt(match, 10, 64)
+	if err != nil || version < 0 {
+		return 0, &requestError{fiber.StatusBadRequest, "If-Match must be the task's ETag"}
+	}
+	return version, nil
+}
+
+// versionFilter matches a task at version. Tasks created before versioning
+// have no version field and count as version 0.
+func versionFilter(version int64) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
+}
+
+// sendConflict answers an update made against an outdated version with the
+// task as it is now
+func sendConflict(c *fiber.Ctx, current models.Task) error {
+	c.Set(fiber.HeaderETag, taskETag(current))
+	return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{"error": "The task was changed by someone else", "task": current})
+}
+
+// DeleteTask moves a task to the trash. It can be restored until the purge job
+// removes it for good.
 func (t *TaskHandler) DeleteTask(c *fiber.Ctx) error {
 	id := c.Params("id")
+	userID := c.Locals("user_id").(primitive.ObjectID)
 
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Task ID"})
 	}
 
-	filter := bson.M{"_id": oid}
-	result, err := t.taskCollection.DeleteOne(c.Context(), filter)
+	now := time.Now()
+	filter := trash.Live(bson.M{"_id": oid})
+	update := bson.M{"$set": bson.M{"deleted_at": now, "deleted_by": userID}}
+	var deletedTask models.Task
+	err = t.taskCollection.FindOneAndUpdate(c.Context(), filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&deletedTask)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete task"})
 	}
 
-	if result.DeletedCount == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
-	}
-
-	go ws.WSManager.Broadcast(fiber.Map{"event": "task_deleted", "task_id": id})
+	event := fiber.Map{"event": "task_deleted", "task_id": id, "purge_at": trash.PurgeAt(now)}
+	go ws.WSManager.Broadcast(event)
+	go webhook.Dispatch(context.Background(), deletedTask.WorkspaceID, models.WebhookTaskDeleted, event)
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Task deleted", "task_id": id})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Task moved to trash", "task_id": id, "purge_at": trash.PurgeAt(now)})
 }
 
 func (t *TaskHandler) GetAllTasks(c *fiber.Ctx) error {
 	var tasks []models.Task
-	cursor, err := t.taskCollection.Find(c.Context(), bson.M{})
+	cursor, err := t.taskCollection.Find(c.Context(), trash.Live(bson.M{}))
 
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch tasks"})
@@ -179,7 +309,7 @@ func (t *TaskHandler) GetUserTasks(c *fiber.Ctx) error {
 	}
 
 	var tasks []models.Task
-	filter := bson.M{"assigned_to": userID}
+	filter := trash.Live(bson.M{"assigned_to": userID})
 	cursor, err := t.taskCollection.Find(c.Context(), filter)
 
 	if err != nil {
@@ -226,7 +356,7 @@ func (t *TaskHandler) GetTaskByID(c *fiber.Ctx) error {
 	}
 
 	var task models.Task
-	err = t.taskCollection.FindOne(c.Context(), bson.M{"_id": objID}).Decode(&task)
+	err = t.taskCollection.FindOne(c.Context(), trash.Live(bson.M{"_id": objID})).Decode(&task)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
@@ -263,6 +393,7 @@ func (t *TaskHandler) GetTaskByID(c *fiber.Ctx) error {
 			}
 		}
 	}
+	c.Set(fiber.HeaderETag, taskETag(task))
 	return c.JSON(fiber.Map{"task": enhancedTask})
 
 }
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"log"
+
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/notification"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// notifyTaskCreated tells assignees and mentioned users about a new task.
+// It runs outside the request, so it must not use the fiber context.
+func notifyTaskCreated(task models.Task, actorID primitive.ObjectID) {
+	ctx := context.Background()
+	notification.Notify(ctx, notification.Event{
+		Type:       models.EventAssigned,
+		Task:       task,
+		ActorID:    actorID,
+		Recipients: task.AssignedTo,
+	})
+	notifyMentions(ctx, task, "", actorID)
+}
+
+// notifyTaskUpdated compares the task before and after an update and sends
+// assignment, status change and mention notifications for what changed.
+func notifyTaskUpdated(before models.Task, after models.Task, actorID primitive.ObjectID) {
+	ctx := context.Background()
+
+	var newAssignees []primitive.ObjectID
+	for _, id := range after.AssignedTo {
+		if !containsID(before.AssignedTo, id) {
+			newAssignees = append(newAssignees, id)
+		}
+	}
+	notification.Notify(ctx, notification.Event{
+		Type:       models.EventAssigned,
+		Task:       after,
+		ActorID:    actorID,
+		Recipients: newAssignees,
+	})
+
+	if before.Status != after.Status {
+		notification.Notify(ctx, notification.Event{
+			Type:       models.EventStatusChanged,
+			Task:       after,
+			ActorID:    actorID,
+			Recipients: append(append([]primitive.ObjectID{}, after.AssignedTo...), after.AssignedBy),
+			OldStatus:  before.Status,
+		})
+	}
+
+	notifyMentions(ctx, after, before.Title+" "+before.Description, actorID)
+}
+
+// notifyMentions notifies users @mentioned in the task who weren't already mentioned in previousText
+func notifyMentions(ctx context.Context, task models.Task, previousText string, actorID primitive.ObjectID) {
+	mentioned, err := notification.MentionedUsers(ctx, task.Title+" "+task.Description)
+	if err != nil {
+		log.Printf("Could not resolve mentions for task %s: %v", task.ID.Hex(), err)
+		return
+	}
+	alreadyMentioned, err := notification.MentionedUsers(ctx, previousText)
+	if err != nil {
+		log.Printf("Could not resolve mentions for task %s: %v", task.ID.Hex(), err)
+		return
+	}
+	var recipients []primitive.ObjectID
+	for _, id := range mentioned {
+		if !containsID(alreadyMentioned, id) {
+			recipients = append(recipients, id)
+		}
+	}
+	notification.Notify(ctx, notification.Event{
+		Type:       models.EventMentioned,
+		Task:       task,
+		ActorID:    actorID,
+		Recipients: recipients,
+	})
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/genai"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/quickadd"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type quickAddRequest struct {
+	Text        string              `json:"text"`
+	Description string              `json:"description"`
+	WorkspaceID *primitive.ObjectID `json:"workspace_id,omitempty"`
+	// TZ is the IANA time zone relative dates are read in, default UTC
+	TZ string `json:"tz"`
+	// AI allows the assistant to be asked when the line is ambiguous, default true
+	AI *bool `json:"ai"`
+}
+
+// QuickAdd creates a task from one line such as "Fix login !high @bob #auth
+// tomorrow at 3pm" (POST /tasks?quick=true). Without assignees the task is
+// assigned to the caller.
+func (t *TaskHandler) QuickAdd(c *fiber.Ctx) error {
+	userId := c.Locals("user_id").(primitive.ObjectID)
+	var input quickAddRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if strings.TrimSpace(input.Text) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "text is required"})
+	}
+	if input.TZ == "" {
+		input.TZ = "UTC"
+	}
+	loc, err := time.LoadLocation(input.TZ)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid tz"})
+	}
+	var members []primitive.ObjectID
+	if input.WorkspaceID != nil {
+		workspace, err := findMemberWorkspace(c.Context(), *input.WorkspaceID, userId)
+		if err != nil {
+			return sendError(c, err)
+		}
+		members = workspace.Members
+	}
+
+	now := time.Now().In(loc)
+	parsed := quickadd.Parse(input.Text, now)
+	usedAI := false
+	if parsed.Ambiguous() && (input.AI == nil || *input.AI) {
+		ctx := aiconfig.WithScope(c.Context(), &userId, input.WorkspaceID)
+		suggestion, err := genai.ParseQuickAdd(ctx, input.Text, now, parsed.Ambiguities)
+		if err != nil {
+			log.Printf("Quick add fallback failed for %q: %v", input.Text, err)
+		} else {
+			parsed, usedAI = suggestion, true
+		}
+	}
+	if parsed.Title == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "The task has no title", "parsed": parsed})
+	}
+
+	assignees := []primitive.ObjectID{userId}
+	if len(parsed.Assignees) > 0 {
+		assignees, err = t.resolveHandles(c, parsed.Assignees, members)
+		if err != nil {
+			return sendError(c, err)
+		}
+	}
+
+	task := models.Task{
+		Title:       parsed.Title,
+		Description: input.Description,
+		AssignedTo:  assignees,
+		WorkspaceID: input.WorkspaceID,
+		Priority:    parsed.Priority,
+		DueDate:     parsed.DueDate,
+		Labels:      parsed.Labels,
+	}
+	if err := t.insertTask(c.Context(), &task, userId); err != nil {
+		return sendError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Task created", "task": task, "parsed": parsed, "used_ai": usedAI})
+}
+
+// resolveHandles maps @handles to users by email, email name, full name with or
+// without spaces, or first name. With members set, only those users are matched.
+func (t *TaskHandler) resolveHandles(c *fiber.Ctx, handles []string, members []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	filter := bson.M{}
+	if members != nil {
+		filter["_id"] = bson.M{"$in": members}
+	}
+	cursor, err := t.userCollection.Find(c.Context(), filter, options.Find().SetProjection(bson.M{"name": 1, "email": 1}))
+	if err != nil {
+		return nil, &requestError{fiber.StatusInternalServerError, "Could not fetch users"}
+	}
+	var users []models.UserRequest
+	if err := cursor.All(c.Context(), &users); err != nil {
+		return nil, &requestError{fiber.StatusInternalServerError, "Failed to parse users"}
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(handles))
+	for _, handle := range handles {
+		var matches []primitive.ObjectID
+		for _, user := range users {
+			if handleMatches(handle, user) {
+				matches = append(matches, user.ID)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return nil, &requestError{fiber.StatusBadRequest, fmt.Sprintf("No user matches @%s", handle)}
+		case 1:
+			ids = append(ids, matches[0])
+		default:
+			return nil, &requestError{fiber.StatusBadRequest, fmt.Sprintf("@%s matches several users, use their email", handle)}
+		}
+	}
+	return ids, nil
+}
+
+func handleMatches(handle string, user models.UserRequest) bool {
+	name := strings.TrimSpace(user.Name)
+	local, _, _ := strings.Cut(user.Email, "@")
+	candidates := []string{user.Email, local, name, strings.ReplaceAll(name, " ", "")}
+	if first, _, found := strings.Cut(name, " "); found {
+		candidates = append(candidates, first)
+	}
+	for _, candidate := range candidates {
+		if candidate != "" && strings.EqualFold(candidate, handle) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,414 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/importer"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"github.com/Atif-27/ai-task-manager/ws"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExportedTask is a task as written by the export endpoint, with users as emails
+// so the file can be imported elsewhere.
+type ExportedTask struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	AssignedTo  []string   `json:"assigned_to"`
+	AssignedBy  string     `json:"assigned_by"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Labels      []string   `json:"labels"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+var exportColumns = []string{"id", "title", "description", "status", "priority", "assigned_to", "assigned_by", "due_date", "labels", "created_at", "updated_at"}
+
+func (e ExportedTask) csvRow() []string {
+	due := ""
+	if e.DueDate != nil {
+		due = e.DueDate.Format(time.RFC3339)
+	}
+	return []string{
+		e.ID, e.Title, e.Description, e.Status, e.Priority,
+		strings.Join(e.AssignedTo, ";"), e.AssignedBy, due, strings.Join(e.Labels, ";"),
+		e.CreatedAt.Format(time.RFC3339), e.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// ExportTasks streams the tasks the caller created or is assigned to.
+// Query params: format=csv|json|ndjson (default csv), status, priority, workspace_id.
+func (t *TaskHandler) ExportTasks(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	format := c.Query("format", "csv")
+	if format != "csv" && format != "json" && format != "ndjson" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "format must be csv, json or ndjson"})
+	}
+
+	filter := bson.M{"$or": []bson.M{{"assigned_to": userID}, {"assigned_by": userID}}}
+	if status := models.StatusType(c.Query("status")); status != "" {
+		if !status.ValidateStatus() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid status"})
+		}
+		filter["status"] = status
+	}
+	if priority := models.PriorityType(c.Query("priority")); priority != "" {
+		if !priority.ValidatePriority() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid priority"})
+		}
+		filter["priority"] = priority
+	}
+	if workspace := c.Query("workspace_id"); workspace != "" {
+		workspaceID, err := primitive.ObjectIDFromHex(workspace)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Workspace ID"})
+		}
+		filter["workspace_id"] = workspaceID
+	}
+
+	emails, err := t.userEmails(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch users"})
+	}
+
+	// The body is written after the handler returns, so the cursor can't use the request context
+	ctx := context.Background()
+	cursor, err := t.taskCollection.Find(ctx, trash.Live(filter))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch tasks"})
+	}
+
+	switch format {
+	case "csv":
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	case "json":
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	case "ndjson":
+		c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	}
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="tasks.`+format+`"`)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cursor.Close(ctx)
+		csvWriter := csv.NewWriter(w)
+		encoder := json.NewEncoder(w)
+
+		switch format {
+		case "csv":
+			csvWriter.Write(exportColumns)
+		case "json":
+			w.WriteString("[")
+		}
+		for count := 0; cursor.Next(ctx); count++ {
+			var task models.Task
+			if err := cursor.Decode(&task); err != nil {
+				log.Printf("Export: failed to decode task: %v", err)
+				continue
+			}
+			exported := toExportedTask(task, emails)
+			switch format {
+			case "csv":
+				csvWriter.Write(exported.csvRow())
+				csvWriter.Flush()
+			case "json":
+				if count > 0 {
+					w.WriteString(",")
+				}
+				encoder.Encode(exported)
+			case "ndjson":
+				encoder.Encode(exported)
+			}
+			w.Flush()
+		}
+		if format == "json" {
+			w.WriteString("]")
+		}
+		if err := cursor.Err(); err != nil {
+			log.Printf("Export: cursor error: %v", err)
+		}
+		w.Flush()
+	})
+	return nil
+}
+
+func toExportedTask(task models.Task, emails map[primitive.ObjectID]string) ExportedTask {
+	assignees := make([]string, 0, len(task.AssignedTo))
+	for _, id := range task.AssignedTo {
+		if email, ok := emails[id]; ok {
+			assignees = append(assignees, email)
+		}
+	}
+	return ExportedTask{
+		ID:          task.ID.Hex(),
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      string(task.Status),
+		Priority:    string(task.Priority),
+		AssignedTo:  assignees,
+		AssignedBy:  emails[task.AssignedBy],
+		DueDate:     task.DueDate,
+		Labels:      task.Labels,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+	}
+}
+
+func (t *TaskHandler) userEmails(ctx context.Context) (map[primitive.ObjectID]string, error) {
+	cursor, err := t.userCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var users []models.UserRequest
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	emails := make(map[primitive.ObjectID]string, len(users))
+	for _, user := range users {
+		emails[user.ID] = user.Email
+	}
+	return emails, nil
+}
+
+// ImportTasks creates tasks from an uploaded CSV or JSON file ("file" form field,
+// or the raw request body). Form or query params:
+//   - format: csv or json (guessed from the file name or content type if omitted)
+//   - mapping: JSON object of task field -> source column, e.g. {"title": "Summary"}
+//   - dry_run: "true" to only validate and return the report
+//   - workspace_id: workspace the tasks are created in
+//
+// Nothing is created unless every row is valid.
+func (t *TaskHandler) ImportTasks(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+
+	data, filename, err := readUpload(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Could not read upload"})
+	}
+	if len(data) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No file provided"})
+	}
+
+	mapping := importer.Mapping{}
+	if raw := formValue(c, "mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "mapping must be a JSON object"})
+		}
+		if err := mapping.Validate(); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	workspaceID, err := optionalWorkspace(c, formValue(c, "workspace_id"), userID)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	format := formValue(c, "format")
+	if format == "" {
+		format = guessFormat(filename, c.Get(fiber.HeaderContentType))
+	}
+	var records []importer.Record
+	switch format {
+	case "csv":
+		records, err = importer.ParseCSV(strings.NewReader(string(data)), mapping)
+	case "json", "ndjson":
+		records, err = importer.ParseJSON(data, mapping)
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "format must be csv or json"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	users, err := importer.LookupUsers(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch users"})
+	}
+	tasks, report := importer.Validate(records, users, true)
+
+	if formValue(c, "dry_run") == "true" {
+		return c.JSON(fiber.Map{"dry_run": true, "report": report, "tasks": tasks})
+	}
+	if report.Invalid > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": "Import has invalid rows, nothing was imported", "report": report})
+	}
+
+	now := time.Now()
+	for i := range tasks {
+		tasks[i].ID = primitive.NewObjectID()
+		tasks[i].AssignedBy = userID
+		tasks[i].WorkspaceID = workspaceID
+		tasks[i].CreatedAt = now
+		tasks[i].UpdatedAt = now
+		tasks[i].StatusHistory = []models.StatusChange{{Status: tasks[i].Status, At: now, By: userID}}
+	}
+	if err := importer.Apply(c.Context(), tasks); err != nil {
+		log.Printf("Import failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not import tasks"})
+	}
+
+	ids := make([]primitive.ObjectID, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	ws.WSManager.Broadcast(fiber.Map{"event": "tasks_imported", "task_ids": ids})
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Tasks imported", "imported": len(tasks), "report": report})
+}
+
+// readUpload returns the "file" form field if present, otherwise the raw body
+func readUpload(c *fiber.Ctx) ([]byte, string, error) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Body(), "", nil
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	return data, fileHeader.Filename, err
+}
+
+// formValue reads a multipart form field, falling back to the query string
+func formValue(c *fiber.Ctx, key string) string {
+	if value := c.FormValue(key); value != "" {
+		return value
+	}
+	return c.Query(key)
+}
+
+func guessFormat(filename string, contentType string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return "csv"
+	case ".json", ".ndjson":
+		return "json"
+	}
+	if strings.Contains(contentType, "csv") {
+		return "csv"
+	}
+	if strings.Contains(contentType, "json") {
+		return "json"
+	}
+	return ""
+}
+
+// optionalWorkspace parses an optional workspace ID and checks the caller belongs to it
+func optionalWorkspace(c *fiber.Ctx, value string, userID primitive.ObjectID) (*primitive.ObjectID, error) {
+	if value == "" {
+		return nil, nil
+	}
+	workspaceID, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		return nil, &requestError{fiber.StatusBadRequest, "Invalid Workspace ID"}
+	}
+	if _, err := findMemberWorkspace(c.Context(), workspaceID, userID); err != nil {
+		return nil, err
+	}
+	return &workspaceID, nil
+}
+
+// ImportFromSource imports an export file of another tool (:source is trello,
+// jira or github). Form or query params:
+//   - status_map, priority_map: JSON objects overriding how source values are mapped
+//   - dry_run: "true" to return the preview without writing anything
+//   - workspace_id: workspace the tasks are imported into
+//
+// Tasks already imported from the same source are updated instead of duplicated.
+// Assignees that don't match a user are dropped and listed in the report.
+func (t *TaskHandler) ImportFromSource(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	source := c.Params("source")
+
+	data, _, err := readUpload(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Could not read upload"})
+	}
+	if len(data) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No file provided"})
+	}
+
+	var overrides importer.Overrides
+	for key, target := range map[string]*map[string]string{"status_map": &overrides.Status, "priority_map": &overrides.Priority} {
+		if raw := formValue(c, key); raw != "" {
+			if err := json.Unmarshal([]byte(raw), target); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": key + " must be a JSON object"})
+			}
+		}
+	}
+	if err := overrides.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	workspaceID, err := optionalWorkspace(c, formValue(c, "workspace_id"), userID)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	records, preview, err := importer.ParseSource(source, data, overrides)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	users, err := importer.LookupUsers(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch users"})
+	}
+	tasks, report := importer.Validate(records, users, false)
+
+	if formValue(c, "dry_run") == "true" {
+		externalIDs := make([]string, len(tasks))
+		for i, task := range tasks {
+			externalIDs[i] = task.ExternalID
+		}
+		existing, err := importer.ExistingExternalIDs(c.Context(), source, workspaceID, externalIDs)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch tasks"})
+		}
+		return c.JSON(fiber.Map{
+			"dry_run": true,
+			"report":  report,
+			"mapping": preview,
+			"creates": len(tasks) - len(existing),
+			"updates": len(existing),
+			"tasks":   tasks,
+		})
+	}
+	if report.Invalid > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": "Import has invalid rows, nothing was imported", "report": report})
+	}
+
+	now := time.Now()
+	for i := range tasks {
+		tasks[i].ID = primitive.NewObjectID()
+		tasks[i].AssignedBy = userID
+		tasks[i].WorkspaceID = workspaceID
+		tasks[i].CreatedAt = now
+		tasks[i].UpdatedAt = now
+		tasks[i].StatusHistory = []models.StatusChange{{Status: tasks[i].Status, At: now, By: userID}}
+	}
+	created, updated, err := importer.Upsert(c.Context(), tasks)
+	if err != nil {
+		log.Printf("Import from %s failed: %v", source, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not import tasks"})
+	}
+	report.Created = created
+	report.Updated = updated
+
+	ws.WSManager.Broadcast(fiber.Map{"event": "tasks_imported", "source": source, "created": created, "updated": updated})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Tasks imported", "report": report, "mapping": preview})
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"github.com/Atif-27/ai-task-manager/webhook"
+	"github.com/Atif-27/ai-task-manager/ws"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TrashedTask is a task in the trash with the time it will be purged
+type TrashedTask struct {
+	models.Task
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+// GetTrash lists the deleted tasks the caller can still restore, most recently
+// deleted first: with workspace_id the workspace's, otherwise the ones they
+// created, are assigned to or deleted themselves.
+func (t *TaskHandler) GetTrash(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	workspaceID, err := optionalWorkspace(c, c.Query("workspace_id"), userID)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	filter := bson.M{"$or": []bson.M{{"assigned_to": userID}, {"assigned_by": userID}, {"deleted_by": userID}}}
+	if workspaceID != nil {
+		filter = bson.M{"workspace_id": *workspaceID}
+	}
+	cursor, err := t.taskCollection.Find(c.Context(), trash.Trashed(filter), options.Find().SetSort(bson.M{"deleted_at": -1}))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch trash"})
+	}
+	var tasks []models.Task
+	if err := cursor.All(c.Context(), &tasks); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to parse tasks"})
+	}
+
+	trashed := make([]TrashedTask, len(tasks))
+	for i, task := range tasks {
+		trashed[i] = TrashedTask{Task: task, PurgeAt: trash.PurgeAt(*task.DeletedAt)}
+	}
+	return c.JSON(fiber.Map{"tasks": trashed, "retention_days": int(trash.Retention().Hours() / 24)})
+}
+
+// RestoreTask takes a task out of the trash, for anyone who could see it there
+func (t *TaskHandler) RestoreTask(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	id := c.Params("id")
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Task ID"})
+	}
+
+	var task models.Task
+	err = t.taskCollection.FindOne(c.Context(), trash.Trashed(bson.M{"_id": oid})).Decode(&task)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found in trash"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch task"})
+	}
+	if err := canRestore(c, task, userID); err != nil {
+		return sendError(c, err)
+	}
+
+	update := bson.M{
+		"$unset": bson.M{"deleted_at": "", "deleted_by": ""},
+		"$set":   bson.M{"updated_at": time.Now()},
+		// Edits made against the task before it was deleted must not apply
+		"$inc": bson.M{"version": 1},
+	}
+	err = t.taskCollection.FindOneAndUpdate(c.Context(), trash.Trashed(bson.M{"_id": oid}), update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&task)
+	if err == mongo.ErrNoDocuments {
+		// Restored or purged since it was fetched
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found in trash"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not restore task"})
+	}
+
+	event := fiber.Map{"event": "task_restored", "task_id": id, "task": task}
+	go ws.WSManager.Broadcast(event)
+	go webhook.Dispatch(context.Background(), task.WorkspaceID, models.WebhookTaskRestored, event)
+	c.Set(fiber.HeaderETag, taskETag(task))
+	return c.JSON(fiber.Map{"message": "Task restored", "task": task})
+}
+
+// canRestore applies the same rule as GetTrash: workspace tasks need membership,
+// others must involve the caller
+func canRestore(c *fiber.Ctx, task models.Task, userID primitive.ObjectID) error {
+	if task.WorkspaceID != nil {
+		_, err := findMemberWorkspace(c.Context(), *task.WorkspaceID, userID)
+		return err
+	}
+	if task.AssignedBy == userID || (task.DeletedBy != nil && *task.DeletedBy == userID) {
+		return nil
+	}
+	for _, assignee := range task.AssignedTo {
+		if assignee == userID {
+			return nil
+		}
+	}
+	return &requestError{fiber.StatusNotFound, "Task not found in trash"}
+}
@@ -0,0 +1,378 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxEntryDuration caps a single manual time entry
+const maxEntryDuration = 24 * time.Hour
+
+type TimeHandler struct {
+	entryCollection *mongo.Collection
+	taskCollection  *mongo.Collection
+	userCollection  *mongo.Collection
+}
+
+// Constructor function for TimeHandler
+func MakeTimeHandler() *TimeHandler {
+	return &TimeHandler{
+		entryCollection: database.GetCollection("time_entry"),
+		taskCollection:  database.GetCollection("task"),
+		userCollection:  database.GetCollection("user"),
+	}
+}
+
+// taskParam parses :id and checks the task exists
+func (h *TimeHandler) taskParam(c *fiber.Ctx) (primitive.ObjectID, error) {
+	taskID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return taskID, &requestError{fiber.StatusBadRequest, "Invalid Task ID"}
+	}
+	count, err := h.taskCollection.CountDocuments(c.Context(), trash.Live(bson.M{"_id": taskID}))
+	if err != nil {
+		return taskID, &requestError{fiber.StatusInternalServerError, "Could not fetch task"}
+	}
+	if count == 0 {
+		return taskID, &requestError{fiber.StatusNotFound, "Task not found"}
+	}
+	return taskID, nil
+}
+
+// addTimeSpent keeps the task's rolled-up total in step with its entries
+func (h *TimeHandler) addTimeSpent(ctx context.Context, taskID primitive.ObjectID, seconds int64) error {
+	_, err := h.taskCollection.UpdateOne(ctx, bson.M{"_id": taskID}, bson.M{"$inc": bson.M{"time_spent_seconds": seconds}})
+	return err
+}
+
+// StartTimer starts the caller's timer on a task. A user has at most one running
+// timer; starting another while one runs is a conflict.
+func (h *TimeHandler) StartTimer(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	taskID, err := h.taskParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	now := time.Now()
+	entryID := primitive.NewObjectID()
+	// The upsert only inserts when no timer is running. Two concurrent starts can
+	// still both miss the running entry; the unique index on running entries
+	// then refuses the second insert.
+	filter := bson.M{"user_id": userID, "ended_at": nil}
+	update := bson.M{"$setOnInsert": bson.M{
+		"_id":              entryID,
+		"task_id":          taskID,
+		"started_at":       now,
+		"duration_seconds": 0,
+		"manual":           false,
+		"created_at":       now,
+	}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var entry models.TimeEntry
+	err = h.entryCollection.FindOneAndUpdate(c.Context(), filter, update, opts).Decode(&entry)
+	if mongo.IsDuplicateKeyError(err) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "A timer is already running, stop it first"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not start timer"})
+	}
+	if entry.ID != entryID {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "A timer is already running, stop it first", "entry": entry})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Timer started", "entry": entry})
+}
+
+// StopTimer stops the caller's running timer on a task
+func (h *TimeHandler) StopTimer(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	taskID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Task ID"})
+	}
+
+	var entry models.TimeEntry
+	err = h.entryCollection.FindOne(c.Context(), bson.M{"user_id": userID, "task_id": taskID, "ended_at": nil}).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No timer running on this task"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch timer"})
+	}
+
+	now := time.Now()
+	entry.EndedAt = &now
+	entry.DurationSeconds = int64(now.Sub(entry.StartedAt).Seconds())
+	result, err := h.entryCollection.UpdateOne(c.Context(),
+		bson.M{"_id": entry.ID, "ended_at": nil},
+		bson.M{"$set": bson.M{"ended_at": now, "duration_seconds": entry.DurationSeconds}})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not stop timer"})
+	}
+	if result.ModifiedCount == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Timer was already stopped"})
+	}
+	if err := h.addTimeSpent(c.Context(), taskID, entry.DurationSeconds); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update task total"})
+	}
+	return c.JSON(fiber.Map{"message": "Timer stopped", "entry": entry})
+}
+
+// GetRunningTimer returns the caller's running timer, or null
+func (h *TimeHandler) GetRunningTimer(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	var entry models.TimeEntry
+	err := h.entryCollection.FindOne(c.Context(), bson.M{"user_id": userID, "ended_at": nil}).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return c.JSON(fiber.Map{"entry": nil})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch timer"})
+	}
+	return c.JSON(fiber.Map{"entry": entry})
+}
+
+// AddEntry records time spent by hand, given either ended_at or minutes
+func (h *TimeHandler) AddEntry(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	taskID, err := h.taskParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+	var input models.TimeEntryRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if input.StartedAt.IsZero() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "started_at is required"})
+	}
+	endedAt := input.StartedAt.Add(time.Duration(input.Minutes) * time.Minute)
+	if input.EndedAt != nil {
+		endedAt = *input.EndedAt
+	}
+	duration := endedAt.Sub(input.StartedAt)
+	if duration <= 0 || duration > maxEntryDuration {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Entry must be longer than zero and at most 24 hours"})
+	}
+	if endedAt.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Entry can't end in the future"})
+	}
+
+	entry := models.TimeEntry{
+		ID:              primitive.NewObjectID(),
+		TaskID:          taskID,
+		UserID:          userID,
+		StartedAt:       input.StartedAt,
+		EndedAt:         &endedAt,
+		DurationSeconds: int64(duration.Seconds()),
+		Note:            input.Note,
+		Manual:          true,
+		CreatedAt:       time.Now(),
+	}
+	if _, err := h.entryCollection.InsertOne(c.Context(), entry); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not add time entry"})
+	}
+	if err := h.addTimeSpent(c.Context(), taskID, entry.DurationSeconds); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update task total"})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Time entry added", "entry": entry})
+}
+
+// DeleteEntry removes one of the caller's own entries
+func (h *TimeHandler) DeleteEntry(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	taskID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Task ID"})
+	}
+	entryID, err := primitive.ObjectIDFromHex(c.Params("entryId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid entry ID"})
+	}
+
+	var entry models.TimeEntry
+	err = h.entryCollection.FindOneAndDelete(c.Context(), bson.M{"_id": entryID, "task_id": taskID, "user_id": userID}).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Time entry not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete time entry"})
+	}
+	if entry.EndedAt != nil {
+		if err := h.addTimeSpent(c.Context(), taskID, -entry.DurationSeconds); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update task total"})
+		}
+	}
+	return c.JSON(fiber.Map{"message": "Time entry deleted", "entry_id": entryID})
+}
+
+// GetTaskEntries lists a task's entries, newest first, with the total per user
+func (h *TimeHandler) GetTaskEntries(c *fiber.Ctx) error {
+	taskID, err := h.taskParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+	cursor, err := h.entryCollection.Find(c.Context(), bson.M{"task_id": taskID}, options.Find().SetSort(bson.M{"started_at": -1}))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch time entries"})
+	}
+	entries := []models.TimeEntry{}
+	if err := cursor.All(c.Context(), &entries); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to parse time entries"})
+	}
+
+	perUser, err := h.totals(c.Context(), bson.M{"task_id": taskID, "ended_at": bson.M{"$ne": nil}}, "user", "")
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not total time entries"})
+	}
+	var total int64
+	for _, t := range perUser {
+		total += t.DurationSeconds
+	}
+	return c.JSON(fiber.Map{"entries": entries, "per_user": perUser, "total_seconds": total})
+}
+
+// TimeReport totals finished time entries over a date range.
+// Query params: from, to (RFC 3339 or YYYY-MM-DD, default the last 7 days),
+// group_by=task|user|day (default task), tz for day grouping, user_id, task_id.
+func (h *TimeHandler) TimeReport(c *fiber.Ctx) error {
+	to := time.Now()
+	from := to.AddDate(0, 0, -7)
+	var err error
+	if value := c.Query("from"); value != "" {
+		if from, err = parseReportDate(value); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid from date"})
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = parseReportDate(value); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid to date"})
+		}
+	}
+	if !from.Before(to) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "from must be before to"})
+	}
+
+	groupBy := c.Query("group_by", "task")
+	if groupBy != "task" && groupBy != "user" && groupBy != "day" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "group_by must be task, user or day"})
+	}
+	tz := c.Query("tz", "UTC")
+	if _, err := time.LoadLocation(tz); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid tz"})
+	}
+
+	match := bson.M{"ended_at": bson.M{"$ne": nil}, "started_at": bson.M{"$gte": from, "$lt": to}}
+	for _, key := range []string{"user_id", "task_id"} {
+		if value := c.Query(key); value != "" {
+			id, err := primitive.ObjectIDFromHex(value)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid " + key})
+			}
+			match[key] = id
+		}
+	}
+
+	totals, err := h.totals(c.Context(), match, groupBy, tz)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not build report"})
+	}
+	var total int64
+	for _, t := range totals {
+		total += t.DurationSeconds
+	}
+	return c.JSON(fiber.Map{
+		"from":          from,
+		"to":            to,
+		"group_by":      groupBy,
+		"totals":        totals,
+		"total_seconds": total,
+	})
+}
+
+func parseReportDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// totals groups entries matching match by task, user or day and labels each group
+// with the task title or user name
+func (h *TimeHandler) totals(ctx context.Context, match bson.M, groupBy string, tz string) ([]models.TimeTotal, error) {
+	var key interface{}
+	switch groupBy {
+	case "task":
+		key = bson.M{"$toString": "$task_id"}
+	case "user":
+		key = bson.M{"$toString": "$user_id"}
+	case "day":
+		key = bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$started_at", "timezone": tz}}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":              key,
+			"duration_seconds": bson.M{"$sum": "$duration_seconds"},
+			"entries":          bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+	cursor, err := h.entryCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	totals := []models.TimeTotal{}
+	if err := cursor.All(ctx, &totals); err != nil {
+		return nil, err
+	}
+	if groupBy == "day" || len(totals) == 0 {
+		return totals, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(totals))
+	for _, t := range totals {
+		if id, err := primitive.ObjectIDFromHex(t.Key); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	labels := map[string]string{}
+	if groupBy == "task" {
+		cursor, err := h.taskCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"title": 1}))
+		if err != nil {
+			return nil, err
+		}
+		var tasks []models.Task
+		if err := cursor.All(ctx, &tasks); err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			labels[task.ID.Hex()] = task.Title
+		}
+	} else {
+		cursor, err := h.userCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"name": 1}))
+		if err != nil {
+			return nil, err
+		}
+		var users []models.UserRequest
+		if err := cursor.All(ctx, &users); err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			labels[user.ID.Hex()] = user.Name
+		}
+	}
+	for i := range totals {
+		totals[i].Label = labels[totals[i].Key]
+	}
+	return totals, nil
+}
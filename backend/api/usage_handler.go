@@ -0,0 +1,120 @@
+package api
+
+import (
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/usage"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type UsageHandler struct {
+	userCollection      *mongo.Collection
+	workspaceCollection *mongo.Collection
+}
+
+// Constructor function for UsageHandler
+func MakeUsageHandler() *UsageHandler {
+	return &UsageHandler{
+		userCollection:      database.GetCollection("user"),
+		workspaceCollection: database.GetCollection("workspace"),
+	}
+}
+
+// Report sums AI token usage and cost. Query params: from, to (RFC3339 or
+// YYYY-MM-DD, default this month so far), group_by=user|workspace|model|use|day
+// (default user), workspace_id, user_id. Without workspace_id it needs an admin.
+func (h *UsageHandler) Report(c *fiber.Ctx) error {
+	workspaceID, err := aiAdminScope(c, c.Query("workspace_id"))
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := now
+	if value := c.Query("from"); value != "" {
+		if from, err = parseReportDate(value); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid from date"})
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = parseReportDate(value); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid to date"})
+		}
+	}
+	groupBy := c.Query("group_by", "user")
+	if groupBy != "user" && groupBy != "workspace" && groupBy != "model" && groupBy != "use" && groupBy != "day" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "group_by must be user, workspace, model, use or day"})
+	}
+
+	match := bson.M{"created_at": bson.M{"$gte": from, "$lt": to}}
+	if workspaceID != nil {
+		match["workspace_id"] = *workspaceID
+	}
+	if value := c.Query("user_id"); value != "" {
+		userID, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid User ID"})
+		}
+		match["user_id"] = userID
+	}
+
+	totals, err := usage.Report(c.Context(), match, groupBy)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not build usage report"})
+	}
+	if groupBy == "user" || groupBy == "workspace" {
+		h.nameTotals(c, totals, groupBy)
+	}
+
+	var sum models.AIUsageTotal
+	for _, total := range totals {
+		sum.Requests += total.Requests
+		sum.PromptTokens += total.PromptTokens
+		sum.OutputTokens += total.OutputTokens
+		sum.TotalTokens += total.TotalTokens
+		sum.CostUSD += total.CostUSD
+	}
+	sum.Key = "total"
+	return c.JSON(fiber.Map{"from": from, "to": to, "group_by": groupBy, "totals": totals, "total": sum})
+}
+
+// nameTotals labels user and workspace rows with their names
+func (h *UsageHandler) nameTotals(c *fiber.Ctx, totals []models.AIUsageTotal, groupBy string) {
+	var ids []primitive.ObjectID
+	for _, total := range totals {
+		if id, err := primitive.ObjectIDFromHex(total.Key); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	collection := h.userCollection
+	if groupBy == "workspace" {
+		collection = h.workspaceCollection
+	}
+	cursor, err := collection.Find(c.Context(), bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return
+	}
+	var named []struct {
+		ID   primitive.ObjectID `bson:"_id"`
+		Name string             `bson:"name"`
+	}
+	if err := cursor.All(c.Context(), &named); err != nil {
+		return
+	}
+	names := make(map[string]string, len(named))
+	for _, n := range named {
+		names[n.ID.Hex()] = n.Name
+	}
+	for i := range totals {
+		totals[i].Name = names[totals[i].Key]
+	}
+}
@@ -8,6 +8,7 @@ import (
 	utils "github.com/Atif-27/ai-task-manager/utilits"
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/bcrypt"
@@ -91,3 +92,47 @@ func (u *UserHandler) GetAllUsers(c *fiber.Ctx) error {
 		"users":  users,
 	})
 }
+
+func (u *UserHandler) GetNotificationPreferences(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	var user models.User
+	err := u.userCollection.FindOne(c.Context(), bson.M{"_id": userID}).Decode(&user)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	}
+	mode := user.NotificationMode
+	if mode == "" {
+		mode = models.NotifyImmediate
+	}
+	return c.JSON(fiber.Map{"notification_mode": mode, "daily_summary": user.DailySummary})
+}
+
+func (u *UserHandler) UpdateNotificationPreferences(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	var input models.NotificationPreferencesRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	fields := bson.M{}
+	if input.NotificationMode != "" {
+		if !input.NotificationMode.ValidateNotificationMode() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "notification_mode must be immediate, digest or off"})
+		}
+		fields["notification_mode"] = input.NotificationMode
+	}
+	if input.DailySummary != nil {
+		fields["daily_summary"] = *input.DailySummary
+	}
+	if len(fields) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Nothing to update"})
+	}
+	update := bson.M{"$set": fields}
+	result, err := u.userCollection.UpdateOne(c.Context(), bson.M{"_id": userID}, update)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update preferences"})
+	}
+	if result.MatchedCount == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	}
+	return c.JSON(fiber.Map{"message": "Preferences updated", "updated": fields})
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/webhook"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WebhookHandler struct {
+	webhookCollection  *mongo.Collection
+	deliveryCollection *mongo.Collection
+}
+
+// Constructor function for WebhookHandler
+func MakeWebhookHandler() *WebhookHandler {
+	return &WebhookHandler{
+		webhookCollection:  database.GetCollection("webhook"),
+		deliveryCollection: database.GetCollection("webhook_delivery"),
+	}
+}
+
+func (h *WebhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	workspaceID, err := workspaceParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	var input models.CreateWebhookRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	parsed, err := url.Parse(input.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "url must be an absolute http(s) URL"})
+	}
+	if len(input.Events) == 0 {
+		input.Events = []string{models.WebhookTaskCreated, models.WebhookTaskUpdated, models.WebhookTaskDeleted, models.WebhookTaskRestored}
+	}
+	for _, event := range input.Events {
+		if !models.ValidateWebhookEvent(event) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unknown event type: " + event})
+		}
+	}
+	if input.Secret == "" {
+		secret := make([]byte, 24)
+		if _, err := rand.Read(secret); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not generate secret"})
+		}
+		input.Secret = hex.EncodeToString(secret)
+	}
+
+	subscription := models.WebhookSubscription{
+		ID:          primitive.NewObjectID(),
+		WorkspaceID: workspaceID,
+		URL:         input.URL,
+		Events:      input.Events,
+		Secret:      input.Secret,
+		Active:      true,
+		CreatedBy:   userID,
+		CreatedAt:   time.Now(),
+	}
+	if _, err := h.webhookCollection.InsertOne(c.Context(), subscription); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create webhook"})
+	}
+	// The secret is only ever returned here
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Webhook created", "webhook": subscription})
+}
+
+func (h *WebhookHandler) GetWebhooks(c *fiber.Ctx) error {
+	workspaceID, err := workspaceParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+	cursor, err := h.webhookCollection.Find(c.Context(), bson.M{"workspace_id": workspaceID})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch webhooks"})
+	}
+	webhooks := []models.WebhookSubscription{}
+	if err := cursor.All(c.Context(), &webhooks); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to parse webhooks"})
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return c.JSON(fiber.Map{"webhooks": webhooks})
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	workspaceID, err := workspaceParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+	webhookID, err := primitive.ObjectIDFromHex(c.Params("webhookId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Webhook ID"})
+	}
+	result, err := h.webhookCollection.DeleteOne(c.Context(), bson.M{"_id": webhookID, "workspace_id": workspaceID})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete webhook"})
+	}
+	if result.DeletedCount == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Webhook not found"})
+	}
+	return c.JSON(fiber.Map{"message": "Webhook deleted", "webhook_id": webhookID})
+}
+
+// GetDeliveries lists the 50 most recent deliveries of a webhook with their attempts
+func (h *WebhookHandler) GetDeliveries(c *fiber.Ctx) error {
+	workspaceID, err := workspaceParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+	webhookID, err := primitive.ObjectIDFromHex(c.Params("webhookId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Webhook ID"})
+	}
+	filter := bson.M{"subscription_id": webhookID, "workspace_id": workspaceID}
+	opts := options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(50)
+	cursor, err := h.deliveryCollection.Find(c.Context(), filter, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch deliveries"})
+	}
+	deliveries := []models.WebhookDelivery{}
+	if err := cursor.All(c.Context(), &deliveries); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to parse deliveries"})
+	}
+	return c.JSON(fiber.Map{"deliveries": deliveries})
+}
+
+func (h *WebhookHandler) Redeliver(c *fiber.Ctx) error {
+	workspaceID, err := workspaceParam(c)
+	if err != nil {
+		return sendError(c, err)
+	}
+	webhookID, err := primitive.ObjectIDFromHex(c.Params("webhookId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Webhook ID"})
+	}
+	deliveryID, err := primitive.ObjectIDFromHex(c.Params("deliveryId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Delivery ID"})
+	}
+
+	var original models.WebhookDelivery
+	filter := bson.M{"_id": deliveryID, "subscription_id": webhookID, "workspace_id": workspaceID}
+	if err := h.deliveryCollection.FindOne(c.Context(), filter).Decode(&original); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Delivery not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch delivery"})
+	}
+	delivery, err := webhook.Redeliver(c.Context(), original)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not queue redelivery"})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Redelivery queued", "delivery": delivery})
+}
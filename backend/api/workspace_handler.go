@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// requestError is an error that carries the HTTP status it should be reported with
+type requestError struct {
+	status  int
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+// sendError writes err as a JSON error response, using its status if it is a requestError
+func sendError(c *fiber.Ctx, err error) error {
+	if reqErr, ok := err.(*requestError); ok {
+		return c.Status(reqErr.status).JSON(fiber.Map{"error": reqErr.message})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
+
+type WorkspaceHandler struct {
+	workspaceCollection *mongo.Collection
+	userCollection      *mongo.Collection
+}
+
+// Constructor function for WorkspaceHandler
+func MakeWorkspaceHandler() *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceCollection: database.GetCollection("workspace"),
+		userCollection:      database.GetCollection("user"),
+	}
+}
+
+// findMemberWorkspace loads a workspace and checks that userID belongs to it
+func findMemberWorkspace(ctx context.Context, workspaceID primitive.ObjectID, userID primitive.ObjectID) (models.Workspace, error) {
+	var workspace models.Workspace
+	err := database.GetCollection("workspace").FindOne(ctx, bson.M{"_id": workspaceID}).Decode(&workspace)
+	if err == mongo.ErrNoDocuments {
+		return workspace, &requestError{fiber.StatusNotFound, "Workspace not found"}
+	}
+	if err != nil {
+		return workspace, &requestError{fiber.StatusInternalServerError, "Could not fetch workspace"}
+	}
+	if !workspace.IsMember(userID) {
+		return workspace, &requestError{fiber.StatusForbidden, "You are not a member of this workspace"}
+	}
+	return workspace, nil
+}
+
+// workspaceParam parses :id and checks the caller is a member of that workspace
+func workspaceParam(c *fiber.Ctx) (primitive.ObjectID, error) {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	workspaceID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return workspaceID, &requestError{fiber.StatusBadRequest, "Invalid Workspace ID"}
+	}
+	if _, err := findMemberWorkspace(c.Context(), workspaceID, userID); err != nil {
+		return workspaceID, err
+	}
+	return workspaceID, nil
+}
+
+func (w *WorkspaceHandler) CreateWorkspace(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	var workspace models.Workspace
+	if err := c.BodyParser(&workspace); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	workspace.Name = strings.TrimSpace(workspace.Name)
+	if workspace.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Workspace name is required"})
+	}
+	workspace.ID = primitive.NewObjectID()
+	workspace.OwnerID = userID
+	workspace.Members = []primitive.ObjectID{userID}
+	workspace.CreatedAt = time.Now()
+	if _, err := w.workspaceCollection.InsertOne(c.Context(), workspace); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create workspace"})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Workspace created", "workspace": workspace})
+}
+
+func (w *WorkspaceHandler) GetUserWorkspaces(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	cursor, err := w.workspaceCollection.Find(c.Context(), bson.M{"members": userID})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not fetch workspaces"})
+	}
+	workspaces := []models.Workspace{}
+	if err := cursor.All(c.Context(), &workspaces); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to parse workspaces"})
+	}
+	return c.JSON(fiber.Map{"workspaces": workspaces})
+}
+
+// AddMember adds an existing user to the workspace. Only the owner can add members.
+func (w *WorkspaceHandler) AddMember(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(primitive.ObjectID)
+	workspaceID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Workspace ID"})
+	}
+	workspace, err := findMemberWorkspace(c.Context(), workspaceID, userID)
+	if err != nil {
+		return sendError(c, err)
+	}
+	if workspace.OwnerID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only the workspace owner can add members"})
+	}
+
+	var input models.AddMemberRequest
+	if err := c.BodyParser(&input); err != nil || input.UserID.IsZero() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	count, err := w.userCollection.CountDocuments(c.Context(), bson.M{"_id": input.UserID})
+	if err != nil || count == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	update := bson.M{"$addToSet": bson.M{"members": input.UserID}}
+	if _, err := w.workspaceCollection.UpdateByID(c.Context(), workspaceID, update); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not add member"})
+	}
+	return c.JSON(fiber.Map{"message": "Member added", "workspace_id": workspaceID, "user_id": input.UserID})
+}
@@ -0,0 +1,199 @@
+// Package attachment stores files on tasks: metadata in Mongo, contents in the
+// configured blob store.
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// defaultMaxMB is the upload limit when ATTACHMENT_MAX_MB is unset
+const defaultMaxMB = 10
+
+var (
+	ErrTooLarge   = errors.New("file is too large")
+	ErrNotAllowed = errors.New("file type is not allowed")
+)
+
+// allowedTypes are the content types that can be attached. Types browsers
+// would render as a page, like HTML and SVG, are left out on purpose.
+var allowedTypes = map[string]bool{
+	"image/png":        true,
+	"image/jpeg":       true,
+	"image/gif":        true,
+	"image/webp":       true,
+	"application/pdf":  true,
+	"text/plain":       true,
+	"text/markdown":    true,
+	"text/csv":         true,
+	"application/json": true,
+	"application/zip":  true,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   true,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         true,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": true,
+}
+
+// extensionTypes name the types that content sniffing can't tell apart, such
+// as Office documents (zip files) and text formats
+var extensionTypes = map[string]string{
+	".md":   "text/markdown",
+	".csv":  "text/csv",
+	".json": "application/json",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
+
+// MaxBytes is the largest file that can be attached, from ATTACHMENT_MAX_MB
+func MaxBytes() int64 {
+	if mb, err := strconv.ParseInt(os.Getenv("ATTACHMENT_MAX_MB"), 10, 64); err == nil && mb > 0 {
+		return mb << 20
+	}
+	return defaultMaxMB << 20
+}
+
+// DetectType works out a file's content type from its first bytes, refined by
+// its extension where the bytes are ambiguous, and checks it is allowed
+func DetectType(filename string, head []byte) (string, error) {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	contentType := sniffed
+	if byExtension, ok := extensionTypes[strings.ToLower(filepath.Ext(filename))]; ok {
+		// Only trust the extension when the bytes agree on the family
+		switch {
+		case sniffed == "application/zip" && strings.HasPrefix(byExtension, "application/vnd.openxmlformats"),
+			sniffed == "text/plain" && (strings.HasPrefix(byExtension, "text/") || byExtension == "application/json"):
+			contentType = byExtension
+		}
+	}
+	if !allowedTypes[contentType] {
+		return "", fmt.Errorf("%w: %s", ErrNotAllowed, sniffed)
+	}
+	return contentType, nil
+}
+
+// cleanFilename keeps the base name of an upload, without path or control characters
+func cleanFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	return name
+}
+
+// Save checks and stores an upload of size bytes on a task
+func Save(ctx context.Context, taskID, userID primitive.ObjectID, filename string, r io.Reader, size int64) (models.Attachment, error) {
+	if size > MaxBytes() {
+		return models.Attachment{}, ErrTooLarge
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return models.Attachment{}, fmt.Errorf("could not read upload: %v", err)
+	}
+	head = head[:n]
+	filename = cleanFilename(filename)
+	contentType, err := DetectType(filename, head)
+	if err != nil {
+		return models.Attachment{}, err
+	}
+
+	store, err := storage.Default()
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	attachment := models.Attachment{
+		ID:          primitive.NewObjectID(),
+		TaskID:      taskID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		UploadedBy:  userID,
+		CreatedAt:   time.Now(),
+	}
+	attachment.Key = "attachments/" + taskID.Hex() + "/" + attachment.ID.Hex()
+	if err := store.Put(ctx, attachment.Key, io.MultiReader(bytes.NewReader(head), r), size, contentType); err != nil {
+		return models.Attachment{}, fmt.Errorf("could not store file: %v", err)
+	}
+	if _, err := database.GetCollection("attachment").InsertOne(ctx, attachment); err != nil {
+		store.Delete(context.Background(), attachment.Key)
+		return models.Attachment{}, fmt.Errorf("could not save attachment: %v", err)
+	}
+	return attachment, nil
+}
+
+// List returns a task's attachments, oldest first
+func List(ctx context.Context, taskID primitive.ObjectID) ([]models.Attachment, error) {
+	cursor, err := database.GetCollection("attachment").Find(ctx, bson.M{"task_id": taskID}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch attachments: %v", err)
+	}
+	attachments := []models.Attachment{}
+	if err := cursor.All(ctx, &attachments); err != nil {
+		return nil, fmt.Errorf("failed to parse attachments: %v", err)
+	}
+	return attachments, nil
+}
+
+// Open streams length bytes of an attachment from offset; a negative length
+// reads to the end
+func Open(ctx context.Context, attachment models.Attachment, offset, length int64) (io.ReadCloser, error) {
+	store, err := storage.Default()
+	if err != nil {
+		return nil, err
+	}
+	return store.Get(ctx, attachment.Key, offset, length)
+}
+
+// Delete removes an attachment's metadata and content
+func Delete(ctx context.Context, attachment models.Attachment) error {
+	if _, err := database.GetCollection("attachment").DeleteOne(ctx, bson.M{"_id": attachment.ID}); err != nil {
+		return fmt.Errorf("could not delete attachment: %v", err)
+	}
+	store, err := storage.Default()
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(ctx, attachment.Key); err != nil {
+		// The metadata is gone, so the blob is only wasted space now
+		log.Printf("Could not delete blob %s: %v", attachment.Key, err)
+	}
+	return nil
+}
+
+// DeleteForTask removes every attachment of a task
+func DeleteForTask(ctx context.Context, taskID primitive.ObjectID) error {
+	attachments, err := List(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		if err := Delete(ctx, attachment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package breakdown
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/importer"
+	"github.com/Atif-27/ai-task-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxItems caps the size of a breakdown
+const MaxItems = 50
+
+// Validate checks a draft can be committed: keys are unique, every parent and
+// dependency exists, and neither the tree nor the dependencies loop. Empty
+// priorities default to medium.
+func Validate(draft *models.Breakdown) error {
+	if len(draft.Items) == 0 {
+		return fmt.Errorf("breakdown has no items")
+	}
+	if len(draft.Items) > MaxItems {
+		return fmt.Errorf("breakdown is limited to %d items", MaxItems)
+	}
+	index := make(map[string]int, len(draft.Items))
+	for i := range draft.Items {
+		item := &draft.Items[i]
+		item.Key = strings.TrimSpace(item.Key)
+		item.Title = strings.TrimSpace(item.Title)
+		if item.Key == "" {
+			return fmt.Errorf("item %d has no key", i+1)
+		}
+		if _, dup := index[item.Key]; dup {
+			return fmt.Errorf("duplicate key %q", item.Key)
+		}
+		index[item.Key] = i
+		if item.Title == "" {
+			return fmt.Errorf("item %q has no title", item.Key)
+		}
+		item.Priority = models.PriorityType(strings.ToLower(string(item.Priority)))
+		if item.Priority == "" {
+			item.Priority = models.MEDIUM
+		}
+		if !item.Priority.ValidatePriority() {
+			return fmt.Errorf("item %q has invalid priority %q", item.Key, item.Priority)
+		}
+		if item.EstimateMinutes < 0 {
+			return fmt.Errorf("item %q has a negative estimate", item.Key)
+		}
+	}
+
+	for _, item := range draft.Items {
+		if item.Parent != "" {
+			if _, ok := index[item.Parent]; !ok {
+				return fmt.Errorf("item %q has unknown parent %q", item.Key, item.Parent)
+			}
+		}
+		for _, dep := range item.DependsOn {
+			if _, ok := index[dep]; !ok {
+				return fmt.Errorf("item %q depends on unknown item %q", item.Key, dep)
+			}
+		}
+	}
+
+	parents := func(key string) []string {
+		if parent := draft.Items[index[key]].Parent; parent != "" {
+			return []string{parent}
+		}
+		return nil
+	}
+	if key, ok := findCycle(draft.Items, parents); ok {
+		return fmt.Errorf("item %q is its own ancestor", key)
+	}
+	dependencies := func(key string) []string { return draft.Items[index[key]].DependsOn }
+	if key, ok := findCycle(draft.Items, dependencies); ok {
+		return fmt.Errorf("dependencies of %q form a cycle", key)
+	}
+	return nil
+}
+
+// findCycle runs a depth-first search over edges and returns a key on a cycle
+func findCycle(items []models.BreakdownItem, edges func(string) []string) (string, bool) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(items))
+	var visit func(string) (string, bool)
+	visit = func(key string) (string, bool) {
+		switch state[key] {
+		case visiting:
+			return key, true
+		case done:
+			return "", false
+		}
+		state[key] = visiting
+		for _, next := range edges(key) {
+			if cycle, ok := visit(next); ok {
+				return cycle, true
+			}
+		}
+		state[key] = done
+		return "", false
+	}
+	for _, item := range items {
+		if cycle, ok := visit(item.Key); ok {
+			return cycle, true
+		}
+	}
+	return "", false
+}
+
+// ToTasks turns a validated draft into tasks with their parent and dependency
+// links resolved. The returned map gives each item's task ID by key.
+func ToTasks(draft models.Breakdown, userID primitive.ObjectID, assignTo []primitive.ObjectID, workspaceID *primitive.ObjectID) ([]models.Task, map[string]primitive.ObjectID) {
+	ids := make(map[string]primitive.ObjectID, len(draft.Items))
+	for _, item := range draft.Items {
+		ids[item.Key] = primitive.NewObjectID()
+	}
+	if assignTo == nil {
+		assignTo = []primitive.ObjectID{}
+	}
+
+	now := time.Now()
+	tasks := make([]models.Task, len(draft.Items))
+	for i, item := range draft.Items {
+		task := models.Task{
+			ID:            ids[item.Key],
+			Title:         item.Title,
+			Description:   item.Description,
+			AssignedTo:    assignTo,
+			AssignedBy:    userID,
+			WorkspaceID:   workspaceID,
+			Status:        models.PENDING,
+			Priority:      item.Priority,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			StatusHistory: []models.StatusChange{{Status: models.PENDING, At: now, By: userID}},
+		}
+		if item.Parent != "" {
+			parentID := ids[item.Parent]
+			task.ParentID = &parentID
+		}
+		for _, dep := range item.DependsOn {
+			task.DependsOn = append(task.DependsOn, ids[dep])
+		}
+		if item.EstimateMinutes > 0 {
+			estimate := item.EstimateMinutes
+			task.EstimateMinutes = &estimate
+		}
+		tasks[i] = task
+	}
+	return tasks, ids
+}
+
+// Commit stores the whole tree or nothing
+func Commit(ctx context.Context, tasks []models.Task) error {
+	return importer.Apply(ctx, tasks)
+}
+
+// Outline renders a validated draft as an indented plain text tree
+func Outline(draft models.Breakdown) string {
+	children := map[string][]models.BreakdownItem{}
+	for _, item := range draft.Items {
+		children[item.Parent] = append(children[item.Parent], item)
+	}
+	var b strings.Builder
+	if draft.Goal != "" {
+		fmt.Fprintf(&b, "Goal: %s\n", draft.Goal)
+	}
+	var write func(parent string, depth int)
+	write = func(parent string, depth int) {
+		for _, item := range children[parent] {
+			fmt.Fprintf(&b, "%s- [%s] %s (%s", strings.Repeat("  ", depth), item.Key, item.Title, item.Priority)
+			if item.EstimateMinutes > 0 {
+				fmt.Fprintf(&b, ", %d min", item.EstimateMinutes)
+			}
+			b.WriteString(")")
+			if len(item.DependsOn) > 0 {
+				fmt.Fprintf(&b, " after %s", strings.Join(item.DependsOn, ", "))
+			}
+			b.WriteString("\n")
+			write(item.Key, depth+1)
+		}
+	}
+	write("", 0)
+	return b.String()
+}
@@ -0,0 +1,194 @@
+// Package bulk applies the same changes to many tasks in one write, for triage
+// sessions that move dozens of tasks at once.
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MaxTasks is the most tasks one request can change
+const MaxTasks = 200
+
+// Error is a problem with the requested operations, as opposed to a failure of
+// the task store
+type Error struct {
+	Message string
+}
+
+func (e Error) Error() string {
+	return e.Message
+}
+
+func invalid(format string, args ...interface{}) error {
+	return Error{Message: fmt.Sprintf(format, args...)}
+}
+
+// Validate normalises the operations and rejects unknown or contradicting ones.
+// Each operation other than add_label may appear once, and delete only alone.
+func Validate(ops []models.BulkOperation) error {
+	if len(ops) == 0 {
+		return invalid("operations are required")
+	}
+	seen := map[string]bool{}
+	for i := range ops {
+		op := &ops[i]
+		switch op.Op {
+		case models.BulkSetStatus:
+			op.Status = models.StatusType(strings.ToLower(strings.TrimSpace(string(op.Status))))
+			if !op.Status.ValidateStatus() {
+				return invalid("invalid status %q", op.Status)
+			}
+		case models.BulkSetPriority:
+			op.Priority = models.PriorityType(strings.ToLower(strings.TrimSpace(string(op.Priority))))
+			if !op.Priority.ValidatePriority() {
+				return invalid("invalid priority %q", op.Priority)
+			}
+		case models.BulkSetAssignees:
+			if op.AssignedTo == nil {
+				op.AssignedTo = []primitive.ObjectID{}
+			}
+		case models.BulkAddLabel:
+			op.Label = strings.TrimSpace(op.Label)
+			if op.Label == "" {
+				return invalid("add_label needs a label")
+			}
+			continue
+		case models.BulkDelete:
+			if len(ops) > 1 {
+				return invalid("delete can't be combined with other operations")
+			}
+		default:
+			return invalid("unknown operation %q", op.Op)
+		}
+		if seen[op.Op] {
+			return invalid("%s appears more than once", op.Op)
+		}
+		seen[op.Op] = true
+	}
+	return nil
+}
+
+// update builds the change the operations make to one task. Status changes are
+// added to the task's history like single updates are.
+func update(task models.Task, ops []models.BulkOperation, userID primitive.ObjectID, now time.Time) bson.M {
+	if ops[0].Op == models.BulkDelete {
+		return bson.M{"$set": bson.M{"deleted_at": now, "deleted_by": userID}}
+	}
+	set := bson.M{"updated_at": now}
+	var labels []string
+	change := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	for _, op := range ops {
+		switch op.Op {
+		case models.BulkSetStatus:
+			set["status"] = op.Status
+			if op.Status != task.Status {
+				change["$push"] = bson.M{"status_history": models.StatusChange{Status: op.Status, At: now, By: userID}}
+			}
+		case models.BulkSetPriority:
+			set["priority"] = op.Priority
+		case models.BulkSetAssignees:
+			set["assigned_to"] = op.AssignedTo
+		case models.BulkAddLabel:
+			labels = append(labels, op.Label)
+		}
+	}
+	if len(labels) > 0 {
+		change["$addToSet"] = bson.M{"labels": bson.M{"$each": labels}}
+	}
+	return change
+}
+
+// Apply makes the operations on every task in one BulkWrite and returns the
+// outcome per task, in order. Tasks trashed since they were loaded are reported
+// as not found. With transactions (replica sets) it is all or nothing and a
+// failure is returned as the error; on a standalone server the tasks that could
+// be written are, and the others are reported as failed.
+func Apply(ctx context.Context, userID primitive.ObjectID, tasks []models.Task, ops []models.BulkOperation) ([]models.BulkResult, error) {
+	if len(tasks) == 0 {
+		return []models.BulkResult{}, nil
+	}
+	now := time.Now()
+	done := models.BulkUpdated
+	if ops[0].Op == models.BulkDelete {
+		done = models.BulkDeleted
+	}
+	ids := make([]primitive.ObjectID, len(tasks))
+	writes := make([]mongo.WriteModel, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(trash.Live(bson.M{"_id": task.ID})).
+			SetUpdate(update(task, ops, userID, now))
+	}
+
+	collection := database.GetCollection("task")
+	var live map[primitive.ObjectID]bool
+	// write reads which tasks are still live, which in a transaction are the ones
+	// the BulkWrite will match, then writes them all
+	write := func(ctx context.Context) error {
+		var err error
+		if live, err = liveIDs(ctx, collection, ids); err != nil {
+			return err
+		}
+		_, err = collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		return err
+	}
+	err := database.WithTransaction(ctx, write)
+	if err == database.ErrNoTransactions {
+		err = write(ctx)
+		var partial mongo.BulkWriteException
+		if errors.As(err, &partial) && partial.WriteConcernError == nil {
+			failed := map[int]string{}
+			for _, writeErr := range partial.WriteErrors {
+				failed[writeErr.Index] = writeErr.Message
+			}
+			return results(tasks, done, live, failed), nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bulk update failed: %v", err)
+	}
+	return results(tasks, done, live, nil), nil
+}
+
+// liveIDs returns which of the tasks aren't trashed
+func liveIDs(ctx context.Context, collection *mongo.Collection, ids []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	cursor, err := collection.Find(ctx, trash.Live(bson.M{"_id": bson.M{"$in": ids}}), options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch tasks: %v", err)
+	}
+	var found []models.Task
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, fmt.Errorf("failed to parse tasks: %v", err)
+	}
+	live := make(map[primitive.ObjectID]bool, len(found))
+	for _, task := range found {
+		live[task.ID] = true
+	}
+	return live, nil
+}
+
+func results(tasks []models.Task, done string, live map[primitive.ObjectID]bool, failed map[int]string) []models.BulkResult {
+	results := make([]models.BulkResult, len(tasks))
+	for i, task := range tasks {
+		results[i] = models.BulkResult{TaskID: task.ID, Status: done}
+		if message, ok := failed[i]; ok {
+			results[i].Status, results[i].Error = models.BulkFailed, message
+		} else if !live[task.ID] {
+			results[i].Status = models.BulkNotFound
+		}
+	}
+	return results
+}
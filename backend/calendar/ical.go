@@ -0,0 +1,129 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Atif-27/ai-task-manager/models"
+)
+
+// Component types a feed can be rendered as. Most calendar apps only display
+// VEVENT; task apps (Apple Reminders, Thunderbird) understand VTODO.
+const (
+	ComponentEvent = "VEVENT"
+	ComponentTodo  = "VTODO"
+)
+
+const (
+	productID = "-//AI Task Manager//Tasks//EN"
+	timestamp = "20060102T150405Z"
+	maxLine   = 75
+)
+
+// Render writes tasks as an RFC 5545 VCALENDAR with one component per task.
+// Tasks without a due date are skipped.
+func Render(name string, component string, tasks []models.Task) []byte {
+	var b bytes.Buffer
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+productID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	writeLine(&b, "X-WR-CALNAME:"+escapeText(name))
+
+	for _, task := range tasks {
+		if task.DueDate == nil {
+			continue
+		}
+		due := task.DueDate.UTC().Format(timestamp)
+		writeLine(&b, "BEGIN:"+component)
+		writeLine(&b, "UID:"+task.ID.Hex()+"@ai-task-manager")
+		writeLine(&b, "DTSTAMP:"+task.UpdatedAt.UTC().Format(timestamp))
+		writeLine(&b, "CREATED:"+task.CreatedAt.UTC().Format(timestamp))
+		writeLine(&b, "LAST-MODIFIED:"+task.UpdatedAt.UTC().Format(timestamp))
+		writeLine(&b, "SUMMARY:"+escapeText(task.Title))
+		if task.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escapeText(task.Description))
+		}
+		writeLine(&b, "PRIORITY:"+priority(task.Priority))
+		if len(task.Labels) > 0 {
+			labels := make([]string, len(task.Labels))
+			for i, label := range task.Labels {
+				labels[i] = escapeText(label)
+			}
+			writeLine(&b, "CATEGORIES:"+strings.Join(labels, ","))
+		}
+
+		if component == ComponentTodo {
+			writeLine(&b, "DUE:"+due)
+			writeLine(&b, "STATUS:"+todoStatus(task.Status))
+			if task.Status == models.COMPLETED {
+				writeLine(&b, "COMPLETED:"+task.UpdatedAt.UTC().Format(timestamp))
+				writeLine(&b, "PERCENT-COMPLETE:100")
+			}
+		} else {
+			// VEVENT has no task status, so a completed task is shown as transparent
+			writeLine(&b, "DTSTART:"+due)
+			writeLine(&b, "DTEND:"+task.DueDate.UTC().Add(30*time.Minute).Format(timestamp))
+			writeLine(&b, "STATUS:CONFIRMED")
+			if task.Status == models.COMPLETED {
+				writeLine(&b, "TRANSP:TRANSPARENT")
+			}
+			writeLine(&b, "X-TASK-STATUS:"+todoStatus(task.Status))
+		}
+		writeLine(&b, "END:"+component)
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+	return b.Bytes()
+}
+
+// priority maps onto the RFC 5545 scale, where 1 is highest and 9 lowest
+func priority(p models.PriorityType) string {
+	switch p {
+	case models.HIGH:
+		return "1"
+	case models.MEDIUM:
+		return "5"
+	default:
+		return "9"
+	}
+}
+
+func todoStatus(s models.StatusType) string {
+	switch s {
+	case models.COMPLETED:
+		return "COMPLETED"
+	case models.INPROGRESS:
+		return "IN-PROCESS"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// writeLine folds content lines longer than 75 octets without splitting a UTF-8
+// character, and terminates them with CRLF
+func writeLine(b *bytes.Buffer, line string) {
+	limit := maxLine
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space, which counts towards the limit
+		limit = maxLine - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,64 @@
+// Command aieval replays the golden AI conversations and reports how well the
+// assistant selects tools and extracts their arguments.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/aieval"
+	"github.com/Atif-27/ai-task-manager/genai"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	var (
+		corpus       = flag.String("corpus", "aieval/corpus", "directory of conversation cases")
+		live         = flag.Bool("live", false, "send the turns to the configured model instead of replaying recorded responses")
+		record       = flag.Bool("record", false, "with -live, save the model's responses back into the cases")
+		jsonOut      = flag.Bool("json", false, "print the report as JSON")
+		minPrecision = flag.Float64("min-precision", 0, "exit non-zero if tool precision is below this")
+		minArgs      = flag.Float64("min-args", 0, "exit non-zero if argument accuracy is below this")
+	)
+	flag.Parse()
+
+	_ = godotenv.Load()
+	if err := aiconfig.Load(); err != nil {
+		log.Fatalf("AI config: %v", err)
+	}
+	cases, err := aieval.LoadCorpus(*corpus)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	opts := aieval.Options{Record: *record}
+	if *live {
+		provider, closeProvider, err := genai.NewGeminiProvider(ctx)
+		if err != nil {
+			log.Fatalf("Gemini: %v", err)
+		}
+		defer closeProvider()
+		opts.Live = provider
+	}
+
+	report, err := aieval.Run(ctx, cases, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *jsonOut {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	} else {
+		report.Print(os.Stdout)
+	}
+
+	if report.Total.Precision() < *minPrecision || report.Total.ArgAccuracy() < *minArgs {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes creates the indexes the queries rely on. Creating an index that
+// already exists is a no-op, so this runs on every start.
+func EnsureIndexes(ctx context.Context) {
+	indexes := map[string][]mongo.IndexModel{
+		"task": {
+			{Keys: bson.D{{Key: "assigned_to", Value: 1}, {Key: "status", Value: 1}}},
+			// The purge job looks for tasks trashed before a cutoff
+			{Keys: bson.D{{Key: "deleted_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+		},
+		// Quota checks sum a user's or workspace's usage since a date
+		"ai_usage": {
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}}},
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		},
+		"attachment": {
+			{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		},
+		"activity": {
+			{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "workspace_ids", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+		// Version numbers are unique per scope, and at most one version is active
+		"ai_config": {
+			{
+				Keys:    bson.D{{Key: "workspace_id", Value: 1}, {Key: "version", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "workspace_id", Value: 1}},
+				Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"active": true}),
+			},
+		},
+		// A user has at most one running timer
+		"time_entry": {
+			{
+				Keys:    bson.D{{Key: "user_id", Value: 1}},
+				Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"ended_at": nil}),
+			},
+		},
+		"ai_guard_event": {
+			{Keys: bson.D{{Key: "kind", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+	}
+	for collection, models := range indexes {
+		if _, err := GetCollection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			log.Printf("Could not create indexes on %s: %v", collection, err)
+		}
+	}
+}
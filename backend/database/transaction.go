@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrNoTransactions is returned by WithTransaction on a standalone server
+var ErrNoTransactions = errors.New("transactions are not supported by this server")
+
+// WithTransaction runs fn in a transaction. It returns ErrNoTransactions without
+// having written anything when the server is a standalone instance.
+func WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := DB.StartSession()
+	if err != nil {
+		return ErrNoTransactions
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	if err != nil && transactionsUnsupported(err) {
+		return ErrNoTransactions
+	}
+	return err
+}
+
+// transactionsUnsupported reports whether err comes from a standalone server refusing a transaction
+func transactionsUnsupported(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == 20 {
+		return true
+	}
+	return strings.Contains(err.Error(), "Transaction numbers are only allowed")
+}
@@ -7,6 +7,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/usage"
 	"github.com/google/generative-ai-go/genai"
 	"google.golang.org/api/option"
 )
@@ -15,45 +17,31 @@ type AITaskSuggestion struct {
 	Title       string   `json:"title"`
 	Description []string `json:"description"`
 	Priority    string   `json:"priority"`
+	// EstimateMinutes is the model's guess of the effort, 0 if it gave none
+	EstimateMinutes int `json:"estimate_minutes"`
 }
 
-func GetAISuggestion(taskTitle string) (AITaskSuggestion, error) {
+func GetAISuggestion(ctx context.Context, taskTitle string) (AITaskSuggestion, error) {
 	fmt.Println("Generating AI task suggestion...")
-	ctx := context.Background()
+	if err := usage.Check(ctx); err != nil {
+		return AITaskSuggestion{}, err
+	}
 	client, err := genai.NewClient(ctx, option.WithAPIKey(os.Getenv("API_KEY")))
 	if err != nil {
 		return AITaskSuggestion{}, fmt.Errorf("failed to create Gemini client: %v", err)
 	}
 	defer client.Close()
 
-	model := client.GenerativeModel("gemini-1.5-flash")
+	model, config := configuredModel(ctx, client, aiconfig.UseSuggestion)
 
-	prompt := fmt.Sprintf(`
-	Analyze the given task title: "%s".
-	
-	Dont interact with the user with something other than  JSON response, Your only job is to:
-	1. Generate a clear and meaningful description **in stepwise bullet points** (at least 4-5 steps) and make it concise and short.
-	2. Determine the priority level (low, medium, high).
-	
-	Respond in strict JSON format:
-	{
-  		"title": "Develop User Authentication",
-  		"description": [
-    	"Step 1: Define authentication requirements.",
-    	"Step 2: Implement JWT-based authentication.",
-    	"Step 3: Set up user roles and permissions.",
-    	"Step 4: Integrate social login (Google, GitHub).",
-    	"Step 5: Implement multi-factor authentication (MFA)."
-  		],
-  		"priority": "high"
-	}
-	`, taskTitle)
+	prompt := renderPrompt(ctx, config, aiconfig.PromptSuggestion, map[string]interface{}{"Title": taskTitle})
 
 	// Generate AI response
 	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
 	if err != nil {
 		return AITaskSuggestion{}, fmt.Errorf("failed to generate AI content: %v", err)
 	}
+	recordUsage(ctx, aiconfig.UseSuggestion, aiconfig.Settings(config, aiconfig.UseSuggestion).Name, resp)
 
 	// Check if the response contains candidates
 	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
@@ -78,6 +66,9 @@ func GetAISuggestion(taskTitle string) (AITaskSuggestion, error) {
 	if aiSuggestion.Title == "" || len(aiSuggestion.Description) == 0 || aiSuggestion.Priority == "" {
 		return AITaskSuggestion{}, fmt.Errorf("invalid AI response: missing required fields")
 	}
+	if aiSuggestion.EstimateMinutes < 0 {
+		aiSuggestion.EstimateMinutes = 0
+	}
 	fmt.Println(aiSuggestion)
 	return aiSuggestion, nil
 }
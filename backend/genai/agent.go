@@ -0,0 +1,93 @@
+package genai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// MaxToolIterations is how many rounds of tool calls one message may take
+// before the model is told to answer with what it has
+const MaxToolIterations = 6
+
+var errToolLimit = errors.New("tool call limit reached for this message; answer with the information you already have")
+
+// Step is one tool call made while answering a message
+type Step struct {
+	// Iteration is the round of tool calls the step belongs to, starting at 1.
+	// Calls the model makes in the same reply share a round and run in parallel.
+	Iteration int                    `json:"iteration"`
+	Tool      string                 `json:"tool"`
+	Args      map[string]interface{} `json:"args,omitempty"`
+	Result    map[string]interface{} `json:"result,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	// Blocked is why the guardrails refused the call; the model is told so
+	Blocked    string `json:"blocked,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Reply is the assistant's answer to a message and the tool calls behind it
+type Reply struct {
+	Text  string `json:"text"`
+	Steps []Step `json:"steps,omitempty"`
+}
+
+// splitResponse returns a model reply's text and the function calls in it
+func splitResponse(res *genai.GenerateContentResponse) (string, []genai.FunctionCall) {
+	var text string
+	var calls []genai.FunctionCall
+	for _, cand := range res.Candidates {
+		if cand.Content == nil {
+			continue
+		}
+		for _, part := range cand.Content.Parts {
+			switch part := part.(type) {
+			case genai.Text:
+				text += string(part)
+			case genai.FunctionCall:
+				calls = append(calls, part)
+			}
+		}
+	}
+	return text, calls
+}
+
+// runTools checks and runs one round of function calls in parallel. The steps are in the
+// order of the calls; the error is the first call that couldn't be run at all.
+func (sm *SessionManager) runTools(ctx context.Context, userSession *UserSession, iteration int, calls []genai.FunctionCall) ([]Step, error) {
+	steps := make([]Step, len(calls))
+	errs := make([]error, len(calls))
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		go func(i int, call genai.FunctionCall) {
+			defer wg.Done()
+			start := time.Now()
+			steps[i] = Step{Iteration: iteration, Tool: call.Name, Args: call.Args}
+			// Refused calls are timed too, their check may query the store
+			defer func() { steps[i].DurationMs = time.Since(start).Milliseconds() }()
+			if refused := checkToolCall(ctx, call); refused != nil {
+				steps[i].Blocked = refused.Error()
+				steps[i].Result = toolError(refused)
+				return
+			}
+			result, err := sm.tools.RunTool(ctx, userSession, call)
+			steps[i].Result = result
+			if err != nil {
+				steps[i].Error = err.Error()
+				errs[i] = err
+			}
+		}(i, call)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return steps, err
+		}
+	}
+	return steps, nil
+}
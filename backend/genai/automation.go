@@ -9,8 +9,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/breakdown"
 	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/guard"
 	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/query"
+	"github.com/Atif-27/ai-task-manager/recommend"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"github.com/Atif-27/ai-task-manager/usage"
 	"github.com/google/generative-ai-go/genai"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -19,17 +26,25 @@ import (
 
 // UserSession holds a user's chat session and related metadata
 type UserSession struct {
-	Session  *genai.ChatSession
-	Model    *genai.GenerativeModel // Store the model instance with the session
+	Session  ChatSession
 	LastUsed time.Time
 	UserID   string
 	Mutex    sync.Mutex
+	// ModelName is the configured model, for usage metering
+	ModelName string
+	// PendingBreakdown is the last goal breakdown drafted in the chat, waiting
+	// for the user to confirm it. Tools run in parallel, so it is guarded by
+	// pendingMutex.
+	PendingBreakdown *models.Breakdown
+	pendingMutex     sync.Mutex
 }
 
 // SessionManager manages multiple user sessions
 type SessionManager struct {
 	sessions    map[string]*UserSession
 	client      *genai.Client
+	provider    Provider
+	tools       ToolRunner
 	mutex       sync.RWMutex
 	cleanupDone chan struct{}
 }
@@ -53,9 +68,11 @@ func GetSessionManager(ctx context.Context) (*SessionManager, error) {
 		manager = &SessionManager{
 			sessions:    make(map[string]*UserSession),
 			client:      client,
+			tools:       liveTools{},
 			mutex:       sync.RWMutex{},
 			cleanupDone: make(chan struct{}),
 		}
+		manager.provider = geminiProvider{client: client}
 
 		// Start a goroutine for session cleanup
 		go manager.sessionCleanup()
@@ -64,8 +81,9 @@ func GetSessionManager(ctx context.Context) (*SessionManager, error) {
 	return manager, initErr
 }
 
-// createNewModel creates a new generative model with the proper configuration
-func (sm *SessionManager) createNewModel() *genai.GenerativeModel {
+// createNewModel creates a new generative model with the proper configuration.
+// The model, system prompt and tool descriptions come from the AI config.
+func createNewModel(ctx context.Context, client *genai.Client, userID string) (*genai.GenerativeModel, string) {
 	// Define the schema for task creation
 	schema := &genai.Schema{
 		Type: genai.TypeObject,
@@ -73,6 +91,7 @@ func (sm *SessionManager) createNewModel() *genai.GenerativeModel {
 			"title":       {Type: genai.TypeString, Description: "The title of the task"},
 			"description": {Type: genai.TypeString, Description: "A detailed description of the task"},
 			"priority":    {Type: genai.TypeString, Description: "Priority level: low, medium, or high"},
+			"parent_task_id": {Type: genai.TypeString, Description: "ID of an existing task to create this one as a subtask of, if the user asked for one"},
 		},
 		Required: []string{"title", "description", "priority"},
 	}
@@ -83,68 +102,106 @@ func (sm *SessionManager) createNewModel() *genai.GenerativeModel {
 		},
     }
 
+	suggestAssigneeSchema := &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"title":       {Type: genai.TypeString, Description: "The title of the task that needs an assignee"},
+			"description": {Type: genai.TypeString, Description: "What the task involves, if known"},
+		},
+		Required: []string{"title"},
+	}
+
+	breakdownGoalSchema := &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"goal": {Type: genai.TypeString, Description: "The high-level goal to break down, with any context the user gave"},
+		},
+		Required: []string{"goal"},
+	}
+	queryTasksSchema := &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"question": {Type: genai.TypeString, Description: "The user's question about tasks, with names and dates as they said them"},
+		},
+		Required: []string{"question"},
+	}
+	commitBreakdownSchema := &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"confirm": {Type: genai.TypeBoolean, Description: "Always true; only call once the user has approved the draft"},
+			"parent_task_id": {Type: genai.TypeString, Description: "ID of an existing task to add the breakdown under, if the user asked for one"},
+		},
+	}
+
+	scope := aiconfig.ScopeFrom(ctx)
+	if userObjID, err := primitive.ObjectIDFromHex(userID); err == nil {
+		ctx = aiconfig.WithScope(ctx, &userObjID, scope.WorkspaceID)
+	}
+	model, config := configuredModel(ctx, client, aiconfig.UseChat)
+
 	taskTool := &genai.Tool{
 		FunctionDeclarations: []*genai.FunctionDeclaration{{
 			Name:        "create_task",
-			Description: "Create a new task with the given details. All fields (title, description, priority) are required.",
+			Description: config.Tools["create_task"],
 			Parameters:  schema,
 		},{
                 Name:        "get_user_tasks",
-                Description: "Get all tasks assigned to the current user.",
+                Description: config.Tools["get_user_tasks"],
                 Parameters:  getUserTasksSchema,
-            },},
+            },{
+			Name:        "suggest_assignee",
+			Description: config.Tools["suggest_assignee"],
+			Parameters:  suggestAssigneeSchema,
+		},{
+			Name:        "breakdown_goal",
+			Description: config.Tools["breakdown_goal"],
+			Parameters:  breakdownGoalSchema,
+		},{
+			Name:        "commit_breakdown",
+			Description: config.Tools["commit_breakdown"],
+			Parameters:  commitBreakdownSchema,
+		},{
+			Name:        "query_tasks",
+			Description: config.Tools["query_tasks"],
+			Parameters:  queryTasksSchema,
+		},},
 	}
 
-	model := sm.client.GenerativeModel("gemini-1.5-pro-latest")
 	model.Tools = []*genai.Tool{taskTool}
 	model.SystemInstruction = &genai.Content{
 		Parts: []genai.Part{
-			genai.Text(`You are a task management assistant. You can help users create tasks and prioritize their existing tasks.
-
-For task creation:
-Before calling the create_task function, ensure all required fields (title, description, priority) are provided.
-If a field is explicitly stated in the user query, extract it directly. If a field can be reasonably inferred with at least 90% certainty, extract it. 
-Only ask the user for missing fields when they cannot be reasonably deduced. Remember that each new message from the user is a response to your previous question.
-The replies of the user will be relevant to the previous question asked. Avoid looping back to previously asked questions.
-You have the freedom to generate the description and priority based on users first input if the user seems in hurry.
-If the user does not specify a priority, default to "medium". Once all necessary fields are obtained, call the function without additional questioning.
-
-For task prioritization:
-When a user asks what tasks they should prioritize today or similar questions about task prioritization, call the get_user_tasks function.
-After receiving the list of tasks, analyze them considering:
-- Priority level (high takes precedence over medium and low)
-- Due dates if available (closer due dates are more urgent)
-- Task status (focus on pending tasks)
-Then recommend which task(s) the user should focus on first, explaining your reasoning in a clear, concise manner.
-`),
+			genai.Text(renderPrompt(ctx, config, aiconfig.PromptChatSystem, nil)),
 		},
 	}
 
-	return model
+	return model, aiconfig.Settings(config, aiconfig.UseChat).Name
 }
 
 // GetOrCreateSession retrieves an existing session or creates a new one
-func (sm *SessionManager) GetOrCreateSession(userID string) *UserSession {
+func (sm *SessionManager) GetOrCreateSession(ctx context.Context, userID string) (*UserSession, error) {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
 	session, exists := sm.sessions[userID]
 	if !exists || time.Since(session.LastUsed) > 30*time.Minute {
-		// Create a new model instance for each session to ensure isolation
-		model := sm.createNewModel()
+		// Start a new chat for each session to ensure isolation
+		chat, modelName, err := sm.provider.StartChat(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start chat: %v", err)
+		}
 		newSession := &UserSession{
-			Session:  model.StartChat(),
-			Model:    model, // Store the model instance with the session
-			LastUsed: time.Now(),
-			UserID:   userID,
+			Session:   chat,
+			LastUsed:  time.Now(),
+			UserID:    userID,
+			ModelName: modelName,
 		}
 		sm.sessions[userID] = newSession
-		return newSession
+		return newSession, nil
 	}
 
 	// Update last used time
 	session.LastUsed = time.Now()
-	return session
+	return session, nil
 }
 
 // UpdateSessionTimestamp updates the last used timestamp for a session
@@ -167,7 +224,9 @@ func (sm *SessionManager) ClearSession(userID string) {
 // Close closes the GenAI client and stops the cleanup goroutine
 func (sm *SessionManager) Close() {
 	close(sm.cleanupDone)
-	sm.client.Close()
+	if sm.client != nil {
+		sm.client.Close()
+	}
 }
 
 // sessionCleanup periodically removes inactive sessions
@@ -205,133 +264,225 @@ type SimpleTask struct {
 }
 
 // ProcessConversation processes a message in the context of a conversation
-func ProcessConversation(ctx context.Context, userMessage string, userID string) (string, error) {
-    // Get or create session manager
-    sm, err := GetSessionManager(ctx)
-    if err != nil {
-        return "", fmt.Errorf("failed to initialize session manager: %v", err)
-    }
+func ProcessConversation(ctx context.Context, userMessage string, userID string) (Reply, error) {
+	// Get or create session manager
+	sm, err := GetSessionManager(ctx)
+	if err != nil {
+		return Reply{}, fmt.Errorf("failed to initialize session manager: %v", err)
+	}
+	return sm.Process(ctx, userMessage, userID)
+}
+
+// Process sends a user's message to their chat session and runs the tools the
+// model calls, feeding their results back until the model answers in text
+func (sm *SessionManager) Process(ctx context.Context, userMessage string, userID string) (Reply, error) {
+	var reply Reply
 
-    // Get or create a chat session for this user
-    userSession := sm.GetOrCreateSession(userID)
+	// Meter and limit usage against the user
+	if userObjID, err := primitive.ObjectIDFromHex(userID); err == nil {
+		ctx = aiconfig.WithScope(ctx, &userObjID, nil)
+	}
+	message, err := guard.Input(userMessage)
+	if err != nil {
+		guard.Block(ctx, models.GuardEvent{Kind: models.GuardInput, UserID: aiconfig.ScopeFrom(ctx).UserID, Reason: err.Error(), Excerpt: userMessage})
+		return reply, err
+	}
+	if err := usage.Check(ctx); err != nil {
+		return reply, err
+	}
 
-    userSession.Mutex.Lock()
-    defer userSession.Mutex.Unlock()
+	// Get or create a chat session for this user
+	userSession, err := sm.GetOrCreateSession(ctx, userID)
+	if err != nil {
+		return reply, err
+	}
 
-    // Send the message in the context of the ongoing conversation
-    res, err := userSession.Session.SendMessage(ctx, genai.Text(userMessage))
-    if err != nil {
-        return "", fmt.Errorf("session.SendMessage: %v", err)
-    }
+	userSession.Mutex.Lock()
+	defer userSession.Mutex.Unlock()
 
-    // Update the session timestamp
-    sm.UpdateSessionTimestamp(userID)
-
-    var aiResponse strings.Builder
-
-    // Process all response parts
-    for _, cand := range res.Candidates {
-        for _, part := range cand.Content.Parts {
-            if textPart, isText := part.(genai.Text); isText {
-                // Append text response
-                aiResponse.WriteString(string(textPart))
-            } else if funcall, ok := part.(genai.FunctionCall); ok {
-                var fnResponse *genai.GenerateContentResponse
-                var fnErr error
-
-                switch funcall.Name {
-                case "create_task":
-                    // Extract task details with proper type checking
-                    title, ok1 := funcall.Args["title"].(string)
-                    description, ok2 := funcall.Args["description"].(string)
-                    priority, ok3 := funcall.Args["priority"].(string)
-
-                    if !ok1 || !ok2 || !ok3 {
-                        return aiResponse.String(), fmt.Errorf("invalid task parameters received from model")
-                    }
-
-                    // Create the task
-                    task, err := CreateTask(title, description, priority, userID)
-                    if err != nil {
-                        // Send error response back to model
-                        fnResponse, fnErr = userSession.Session.SendMessage(ctx, genai.FunctionResponse{
-                            Name: funcall.Name,
-                            Response: map[string]interface{}{
-                                "success": false,
-                                "error":   err.Error(),
-                            },
-                        })
-                    } else {
-                        // Send success response back to model
-                        fnResponse, fnErr = userSession.Session.SendMessage(ctx, genai.FunctionResponse{
-                            Name: funcall.Name,
-                            Response: map[string]interface{}{
-                                "success": true,
-                                "taskId":  task.ID.Hex(),
-                                "message": "Task created successfully",
-                            },
-                        })
-                    }
-
-                case "get_user_tasks":
-                    // Get tasks for the user
-                    tasks, err := GetUserTasks(userID)
-                    if err != nil {
-                        // Send error response back to model
-                        fnResponse, fnErr = userSession.Session.SendMessage(ctx, genai.FunctionResponse{
-                            Name: funcall.Name,
-                            Response: map[string]interface{}{
-                                "success": false,
-                                "error":   err.Error(),
-                            },
-                        })
-                    } else {
-                        // Create a text representation of tasks instead of trying to send complex objects
-                        taskSummary := "Tasks:\n"
-                        for _, task := range tasks {
-                            taskSummary += fmt.Sprintf("- ID: %s\n  Title: %s\n  Description: %s\n  Priority: %s\n  Status: %s\n  Created: %s\n\n",
-                                task.ID.Hex(),
-                                task.Title,
-                                task.Description,
-                                string(task.Priority),
-                                string(task.Status),
-                                task.CreatedAt.Format(time.RFC3339))
-                        }
-                        
-                        // Send success response back to model
-                        fnResponse, fnErr = userSession.Session.SendMessage(ctx, genai.FunctionResponse{
-                            Name: funcall.Name,
-                            Response: map[string]interface{}{
-                                "success": true,
-                                "summary": taskSummary,
-                            },
-                        })
-                    }
-
-                default:
-                    return aiResponse.String(), fmt.Errorf("unknown function call: %s", funcall.Name)
-                }
-
-                if fnErr != nil {
-                    log.Printf("Error sending function response for %s: %v", funcall.Name, fnErr)
-                    continue
-                }
-
-                // Process the model's response to the function call
-                if fnResponse != nil {
-                    for _, fnCand := range fnResponse.Candidates {
-                        for _, fnPart := range fnCand.Content.Parts {
-                            if textPart, isText := fnPart.(genai.Text); isText {
-                                aiResponse.WriteString(string(textPart))
-                            }
-                        }
-                    }
-                }
-            }
-        }
-    }
+	// Send the message in the context of the ongoing conversation
+	res, err := sendMessage(ctx, userSession, genai.Text(message))
+	if err != nil {
+		return reply, fmt.Errorf("session.SendMessage: %v", err)
+	}
+
+	// Update the session timestamp
+	sm.UpdateSessionTimestamp(userID)
+
+	var aiResponse strings.Builder
+	for iteration := 1; ; iteration++ {
+		text, calls := splitResponse(res)
+		aiResponse.WriteString(text)
+		if len(calls) == 0 {
+			break
+		}
+
+		var results []genai.Part
+		if iteration > MaxToolIterations {
+			// Every call must be answered for the chat history to stay valid,
+			// so refuse the rest and ask the model to wrap up
+			log.Printf("User %s hit the tool step limit", userID)
+			for _, call := range calls {
+				results = append(results, genai.FunctionResponse{Name: call.Name, Response: toolError(errToolLimit)})
+			}
+		} else {
+			steps, err := sm.runTools(ctx, userSession, iteration, calls)
+			reply.Steps = append(reply.Steps, steps...)
+			if err != nil {
+				reply.Text = aiResponse.String()
+				return reply, err
+			}
+			for _, step := range steps {
+				results = append(results, genai.FunctionResponse{Name: step.Tool, Response: step.Result})
+			}
+		}
+
+		res, err = sendMessage(ctx, userSession, results...)
+		if err != nil {
+			reply.Text = aiResponse.String()
+			return reply, fmt.Errorf("sending tool results: %v", err)
+		}
+		if iteration > MaxToolIterations {
+			text, _ := splitResponse(res)
+			aiResponse.WriteString(text)
+			break
+		}
+	}
 
-    return aiResponse.String(), nil
+	reply.Text = aiResponse.String()
+	return reply, nil
+}
+
+// liveTools runs the chat tools against the task store
+type liveTools struct{}
+
+// toolError is the response sent to the model when a tool fails
+func toolError(err error) map[string]interface{} {
+	return map[string]interface{}{
+		"success": false,
+		"error":   err.Error(),
+	}
+}
+
+// RunTool executes one function call. Tool failures are reported to the model;
+// an error is only returned for calls that can't be run at all.
+func (liveTools) RunTool(ctx context.Context, userSession *UserSession, funcall genai.FunctionCall) (map[string]interface{}, error) {
+	userID := userSession.UserID
+	switch funcall.Name {
+	case "create_task":
+		// Extract task details with proper type checking
+		title, ok1 := funcall.Args["title"].(string)
+		description, ok2 := funcall.Args["description"].(string)
+		priority, ok3 := funcall.Args["priority"].(string)
+
+		if !ok1 || !ok2 || !ok3 {
+			return nil, fmt.Errorf("invalid task parameters received from model")
+		}
+
+		// Create the task
+		parent, err := parentTask(ctx, funcall.Args)
+		if err != nil {
+			return toolError(err), nil
+		}
+		task, err := CreateTask(title, description, priority, userID, parent)
+		if err != nil {
+			return toolError(err), nil
+		}
+		return map[string]interface{}{
+			"success": true,
+			"taskId":  task.ID.Hex(),
+			"message": "Task created successfully",
+		}, nil
+
+	case "get_user_tasks":
+		// Get tasks for the user
+		tasks, err := GetUserTasks(userID)
+		if err != nil {
+			return toolError(err), nil
+		}
+		// Create a text representation of tasks instead of trying to send complex objects
+		// Titles and descriptions may be written by other users
+		taskSummary := "Tasks:\n"
+		for _, task := range tasks {
+			taskSummary += fmt.Sprintf("- ID: %s\n  Title: %s\n  Description: %s\n  Priority: %s\n  Status: %s\n  Created: %s\n\n",
+				task.ID.Hex(),
+				guard.Untrusted(ctx, &task.ID, task.Title),
+				guard.Untrusted(ctx, &task.ID, task.Description),
+				string(task.Priority),
+				string(task.Status),
+				task.CreatedAt.Format(time.RFC3339))
+		}
+		return untrustedResult("summary", taskSummary), nil
+
+	case "suggest_assignee":
+		title, _ := funcall.Args["title"].(string)
+		description, _ := funcall.Args["description"].(string)
+		candidates, err := recommend.Suggest(ctx, recommend.Request{Title: title, Description: description, Limit: 3})
+		if err != nil {
+			return toolError(err), nil
+		}
+		candidateSummary := "Candidates, best first:\n"
+		// Names and the task titles in the reasons are written by users
+		for _, candidate := range candidates {
+			candidateSummary += fmt.Sprintf("- %s (score %.2f): %s\n",
+				guard.Untrusted(ctx, nil, candidate.Name),
+				candidate.Score,
+				guard.Untrusted(ctx, nil, strings.Join(candidate.Reasons, "; ")))
+		}
+		return untrustedResult("candidates", candidateSummary), nil
+
+	case "query_tasks":
+		question, _ := funcall.Args["question"].(string)
+		userObjID, err := primitive.ObjectIDFromHex(userID)
+		if err != nil {
+			return toolError(err), nil
+		}
+		_, tasks, err := QueryTasks(ctx, userObjID, question)
+		if err != nil {
+			return toolError(err), nil
+		}
+		return untrustedResult("tasks", query.Describe(ctx, tasks)), nil
+
+	case "breakdown_goal":
+		userSession.pendingMutex.Lock()
+		defer userSession.pendingMutex.Unlock()
+		goal, _ := funcall.Args["goal"].(string)
+		draft, err := GenerateBreakdown(ctx, goal)
+		if err == nil {
+			err = breakdown.Validate(&draft)
+		}
+		if err != nil {
+			return toolError(err), nil
+		}
+		userSession.PendingBreakdown = &draft
+		return map[string]interface{}{
+			"success": true,
+			"outline": breakdown.Outline(draft),
+		}, nil
+
+	case "commit_breakdown":
+		userSession.pendingMutex.Lock()
+		defer userSession.pendingMutex.Unlock()
+		if userSession.PendingBreakdown == nil {
+			return toolError(fmt.Errorf("there is no drafted breakdown to create")), nil
+		}
+		parent, err := parentTask(ctx, funcall.Args)
+		if err != nil {
+			return toolError(err), nil
+		}
+		if err := commitBreakdown(ctx, *userSession.PendingBreakdown, userID, parent); err != nil {
+			return toolError(err), nil
+		}
+		count := len(userSession.PendingBreakdown.Items)
+		userSession.PendingBreakdown = nil
+		return map[string]interface{}{
+			"success": true,
+			"created": count,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown function call: %s", funcall.Name)
+	}
 }
 
 // Task represents a task in our system
@@ -343,8 +494,72 @@ type Task struct {
 	CreatedAt   time.Time
 }
 
-// CreateTask creates a new task from conversation extracted details
-func CreateTask(title string, description string, priority string, userID string) (*models.Task, error) {
+// sendMessage sends parts to the user's chat session and records the tokens used
+func sendMessage(ctx context.Context, userSession *UserSession, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+	resp, err := userSession.Session.SendMessage(ctx, parts...)
+	if err == nil {
+		recordUsage(ctx, aiconfig.UseChat, userSession.ModelName, resp)
+	}
+	return resp, err
+}
+
+// TaskCreated announces a task the chat created the way the API announces its
+// own: over the WebSocket, to webhooks and to the users it concerns. genai
+// can't reach those, so main sets it; by default nothing is announced.
+var TaskCreated = func(task models.Task, actorID primitive.ObjectID) {}
+
+// parentTask loads the task named by a tool call's parent_task_id, nil without
+// one. checkToolCall has already made sure the user can see it.
+func parentTask(ctx context.Context, args map[string]interface{}) (*models.Task, error) {
+	id, _ := args["parent_task_id"].(string)
+	if id == "" {
+		return nil, nil
+	}
+	parentID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parent task ID: %v", err)
+	}
+	var parent models.Task
+	if err := database.GetCollection("task").FindOne(ctx, trash.Live(bson.M{"_id": parentID})).Decode(&parent); err != nil {
+		return nil, fmt.Errorf("parent task %s was not found", id)
+	}
+	return &parent, nil
+}
+
+// commitBreakdown creates a drafted breakdown assigned to the chatting user,
+// with its top-level tasks under parent when one is given
+func commitBreakdown(ctx context.Context, draft models.Breakdown, userID string, parent *models.Task) error {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %v", err)
+	}
+	if err := breakdown.Validate(&draft); err != nil {
+		return err
+	}
+	var workspaceID *primitive.ObjectID
+	if parent != nil {
+		workspaceID = parent.WorkspaceID
+	}
+	tasks, _ := breakdown.ToTasks(draft, userObjID, []primitive.ObjectID{userObjID}, workspaceID)
+	if parent != nil {
+		for i := range tasks {
+			if tasks[i].ParentID == nil {
+				tasks[i].ParentID = &parent.ID
+			}
+		}
+	}
+	if err := breakdown.Commit(ctx, tasks); err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		TaskCreated(task, userObjID)
+	}
+	return nil
+}
+
+// CreateTask creates a new task from conversation extracted details, as a
+// subtask of parent when one is given
+func CreateTask(title string, description string, priority string, userID string, parent *models.Task) (*models.Task, error) {
 	ctx := context.Background()
 	taskCollection := database.GetCollection("task")
 	userObjID, err := primitive.ObjectIDFromHex(userID)
@@ -373,10 +588,16 @@ func CreateTask(title string, description string, priority string, userID string
 		AssignedTo:  []primitive.ObjectID{},
 		ID:          primitive.NewObjectID(),
 	}
+	task.StatusHistory = []models.StatusChange{{Status: task.Status, At: task.CreatedAt, By: userObjID}}
+	if parent != nil {
+		task.ParentID = &parent.ID
+		task.WorkspaceID = parent.WorkspaceID
+	}
 	_, err = taskCollection.InsertOne(ctx, task)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create task: %v", err)
 	}
+	TaskCreated(task, userObjID)
 	return &task, nil
 }
 
@@ -390,7 +611,7 @@ func GetUserTasks(userID string) ([]models.Task, error) {
         return nil, fmt.Errorf("invalid user ID: %v", err)
     }
     
-    filter := bson.M{"assigned_to": userObjID}
+    filter := trash.Live(bson.M{"assigned_to": userObjID})
     cursor, err := taskCollection.Find(ctx, filter)
     if err != nil {
         return nil, fmt.Errorf("could not fetch assigned tasks: %v", err)
@@ -0,0 +1,77 @@
+package genai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/usage"
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+// Gemini schemas can't be recursive, so the tree is a flat list linked by keys
+var breakdownSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"items": {
+			Type: genai.TypeArray,
+			Items: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"key":              {Type: genai.TypeString, Description: "Short unique id such as t1, t2"},
+					"parent":           {Type: genai.TypeString, Description: "Key of the parent task, empty for top-level tasks"},
+					"title":            {Type: genai.TypeString},
+					"description":      {Type: genai.TypeString},
+					"priority":         {Type: genai.TypeString, Enum: []string{"low", "medium", "high"}},
+					"estimate_minutes": {Type: genai.TypeInteger, Description: "Estimated effort for one person"},
+					"depends_on":       {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}, Description: "Keys of tasks that must be finished first"},
+				},
+				Required: []string{"key", "title", "description", "priority", "estimate_minutes", "depends_on"},
+			},
+		},
+	},
+	Required: []string{"items"},
+}
+
+// GenerateBreakdown asks the model to split a goal into a tree of tasks. The
+// result is a draft; breakdown.Validate checks it before anything is created.
+func GenerateBreakdown(ctx context.Context, goal string) (models.Breakdown, error) {
+	if err := usage.Check(ctx); err != nil {
+		return models.Breakdown{}, err
+	}
+	client, err := genai.NewClient(ctx, option.WithAPIKey(os.Getenv("API_KEY")))
+	if err != nil {
+		return models.Breakdown{}, fmt.Errorf("failed to create Gemini client: %v", err)
+	}
+	defer client.Close()
+
+	model, config := configuredModel(ctx, client, aiconfig.UseBreakdown)
+	model.ResponseMIMEType = "application/json"
+	model.ResponseSchema = breakdownSchema
+
+	prompt := renderPrompt(ctx, config, aiconfig.PromptBreakdown, map[string]interface{}{"Goal": goal})
+
+	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return models.Breakdown{}, fmt.Errorf("failed to generate AI content: %v", err)
+	}
+	recordUsage(ctx, aiconfig.UseBreakdown, aiconfig.Settings(config, aiconfig.UseBreakdown).Name, resp)
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return models.Breakdown{}, fmt.Errorf("no response from Gemini API")
+	}
+	text, ok := resp.Candidates[0].Content.Parts[0].(genai.Text)
+	if !ok {
+		return models.Breakdown{}, fmt.Errorf("unexpected response from Gemini API")
+	}
+
+	draft := models.Breakdown{Goal: goal}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(text))), &draft); err != nil {
+		return models.Breakdown{}, fmt.Errorf("failed to parse AI response: %v", err)
+	}
+	return draft, nil
+}
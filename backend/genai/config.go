@@ -0,0 +1,60 @@
+package genai
+
+import (
+	"context"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/usage"
+	"github.com/google/generative-ai-go/genai"
+)
+
+var harmCategories = map[string]genai.HarmCategory{
+	"harassment":        genai.HarmCategoryHarassment,
+	"hate_speech":       genai.HarmCategoryHateSpeech,
+	"sexually_explicit": genai.HarmCategorySexuallyExplicit,
+	"dangerous_content": genai.HarmCategoryDangerousContent,
+}
+
+var harmThresholds = map[string]genai.HarmBlockThreshold{
+	"block_none":             genai.HarmBlockNone,
+	"block_only_high":        genai.HarmBlockOnlyHigh,
+	"block_medium_and_above": genai.HarmBlockMediumAndAbove,
+	"block_low_and_above":    genai.HarmBlockLowAndAbove,
+}
+
+// configuredModel returns the model for a use set up from the config that
+// applies to ctx's scope, along with that config for rendering prompts
+func configuredModel(ctx context.Context, client *genai.Client, use string) (*genai.GenerativeModel, models.AIConfig) {
+	config := aiconfig.Resolve(ctx)
+	settings := aiconfig.Settings(config, use)
+	model := client.GenerativeModel(settings.Name)
+	model.Temperature = settings.Temperature
+	model.MaxOutputTokens = settings.MaxOutputTokens
+	for category, threshold := range settings.Safety {
+		model.SafetySettings = append(model.SafetySettings, &genai.SafetySetting{
+			Category:  harmCategories[category],
+			Threshold: harmThresholds[threshold],
+		})
+	}
+	return model, config
+}
+
+// recordUsage meters the tokens of a response against ctx's scope
+func recordUsage(ctx context.Context, use string, modelName string, resp *genai.GenerateContentResponse) {
+	if resp == nil || resp.UsageMetadata == nil {
+		return
+	}
+	meta := resp.UsageMetadata
+	usage.Record(ctx, use, modelName, int64(meta.PromptTokenCount), int64(meta.CandidatesTokenCount), int64(meta.TotalTokenCount))
+}
+
+// renderPrompt fills in a prompt template with the common variables for ctx's
+// scope and the call's own variables
+func renderPrompt(ctx context.Context, config models.AIConfig, name string, vars map[string]interface{}) string {
+	all := aiconfig.Vars(ctx)
+	for key, value := range vars {
+		all[key] = value
+	}
+	return aiconfig.Render(config, name, all)
+}
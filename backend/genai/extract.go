@@ -0,0 +1,92 @@
+package genai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/usage"
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+var extractSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"items": {
+			Type: genai.TypeArray,
+			Items: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"title":       {Type: genai.TypeString},
+					"description": {Type: genai.TypeString},
+					"owner":       {Type: genai.TypeString, Description: "Name or email as written in the document, empty if nobody"},
+					"due_date":    {Type: genai.TypeString, Description: "YYYY-MM-DD, empty if no date is given"},
+					"priority":    {Type: genai.TypeString, Enum: []string{"low", "medium", "high"}},
+					"quote":       {Type: genai.TypeString, Description: "The sentence the item comes from"},
+				},
+				Required: []string{"title", "description", "owner", "due_date", "priority", "quote"},
+			},
+		},
+	},
+	Required: []string{"items"},
+}
+
+// Document is a source of action items: pasted Text, or Data of MIMEType for
+// files the model reads itself, such as PDFs and images
+type Document struct {
+	Text     string
+	Data     []byte
+	MIMEType string
+}
+
+// ExtractTasks asks the model for the action items in a document. people are
+// names of users the owners are likely to be, to steer the guesses.
+func ExtractTasks(ctx context.Context, doc Document, people []string) ([]models.ExtractedItem, error) {
+	if err := usage.Check(ctx); err != nil {
+		return nil, err
+	}
+	client, err := genai.NewClient(ctx, option.WithAPIKey(os.Getenv("API_KEY")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %v", err)
+	}
+	defer client.Close()
+
+	model, config := configuredModel(ctx, client, aiconfig.UseExtract)
+	model.ResponseMIMEType = "application/json"
+	model.ResponseSchema = extractSchema
+
+	prompt := renderPrompt(ctx, config, aiconfig.PromptExtract, map[string]interface{}{
+		"Document": doc.Text,
+		"People":   strings.Join(people, ", "),
+	})
+	parts := []genai.Part{genai.Text(prompt)}
+	if doc.Text == "" {
+		parts = append(parts, genai.Blob{MIMEType: doc.MIMEType, Data: doc.Data})
+	}
+
+	resp, err := model.GenerateContent(ctx, parts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate AI content: %v", err)
+	}
+	recordUsage(ctx, aiconfig.UseExtract, aiconfig.Settings(config, aiconfig.UseExtract).Name, resp)
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("no response from Gemini API")
+	}
+	text, ok := resp.Candidates[0].Content.Parts[0].(genai.Text)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response from Gemini API")
+	}
+
+	var parsed struct {
+		Items []models.ExtractedItem `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(text))), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse AI response: %v", err)
+	}
+	return parsed.Items, nil
+}
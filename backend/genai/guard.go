@@ -0,0 +1,74 @@
+package genai
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/guard"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// toolRules are the arguments each chat tool accepts. Calls to tools missing
+// here are refused, and every argument holding a task ID must be listed in
+// TaskIDs so the user's access to the task is checked.
+var toolRules = map[string]guard.ArgRule{
+	"create_task": {
+		Required: []string{"title", "description", "priority"},
+		Enums: map[string]func(string) bool{
+			"priority": func(s string) bool { return models.PriorityType(strings.ToLower(s)).ValidatePriority() },
+		},
+		TaskIDs: []string{"parent_task_id"},
+	},
+	"get_user_tasks":   {},
+	"suggest_assignee": {Required: []string{"title"}},
+	"query_tasks":      {Required: []string{"question"}},
+	"breakdown_goal":   {Required: []string{"goal"}},
+	"commit_breakdown": {TaskIDs: []string{"parent_task_id"}},
+}
+
+// checkToolCall refuses calls to unknown tools, calls with invalid arguments and
+// calls targeting tasks the user can't see, and records them for review
+func checkToolCall(ctx context.Context, call genai.FunctionCall) error {
+	scope := aiconfig.ScopeFrom(ctx)
+	event := models.GuardEvent{Kind: models.GuardToolArgs, UserID: scope.UserID, Tool: call.Name, Args: call.Args}
+
+	rule, ok := toolRules[call.Name]
+	var err error
+	if !ok {
+		err = &guard.Error{Message: "unknown tool " + call.Name}
+	} else if err = guard.CheckArgs(rule, call.Args); err == nil && namesTask(rule, call.Args) {
+		if scope.UserID == nil {
+			err = &guard.Error{Message: "tasks can't be looked up without a user"}
+		} else {
+			err = guard.CheckTaskAccess(ctx, rule, call.Args, *scope.UserID)
+		}
+		event.Kind = models.GuardToolAccess
+	}
+	if err != nil {
+		event.Reason = err.Error()
+		guard.Block(ctx, event)
+	}
+	return err
+}
+
+// namesTask reports whether a call sets any of its rule's task ID arguments
+func namesTask(rule guard.ArgRule, args map[string]interface{}) bool {
+	for _, name := range rule.TaskIDs {
+		if value, ok := args[name].(string); ok && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// untrustedResult wraps a tool result carrying other users' content in
+// delimiters and reminds the model it is data
+func untrustedResult(key string, data string) map[string]interface{} {
+	return map[string]interface{}{
+		"success": true,
+		key:       guard.Delimit(data),
+		"note":    guard.Note,
+	}
+}
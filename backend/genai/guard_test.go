@@ -0,0 +1,49 @@
+package genai
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/google/generative-ai-go/genai"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCheckToolCallChecksTaskIDs(t *testing.T) {
+	user := primitive.NewObjectID()
+	ctx := aiconfig.WithScope(context.Background(), &user, nil)
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	tests := []struct {
+		tool string
+		args map[string]interface{}
+	}{
+		{"create_task", map[string]interface{}{"title": "Write tests", "description": "For the guard", "priority": "high", "parent_task_id": "task-1"}},
+		{"commit_breakdown", map[string]interface{}{"confirm": true, "parent_task_id": "task-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			logged.Reset()
+			err := checkToolCall(ctx, genai.FunctionCall{Name: tt.tool, Args: tt.args})
+			if err == nil {
+				t.Fatal("a call with an invalid task ID was allowed")
+			}
+			if !strings.Contains(logged.String(), "tool_access") {
+				t.Errorf("the refusal was not logged as a tool access, got %q", logged.String())
+			}
+
+			// Without the ID the store is never asked
+			delete(tt.args, "parent_task_id")
+			if err := checkToolCall(ctx, genai.FunctionCall{Name: tt.tool, Args: tt.args}); err != nil {
+				t.Errorf("got %v without a task ID", err)
+			}
+		})
+	}
+}
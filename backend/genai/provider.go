@@ -0,0 +1,62 @@
+package genai
+
+import (
+	"context"
+	"os"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+// ChatSession is one ongoing conversation with a model. *genai.ChatSession
+// implements it.
+type ChatSession interface {
+	SendMessage(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error)
+}
+
+// Provider starts chat sessions for users. It returns the session and the name
+// of the model behind it, for usage metering.
+type Provider interface {
+	StartChat(ctx context.Context, userID string) (ChatSession, string, error)
+}
+
+// ToolRunner executes the function calls the model makes and returns the
+// response sent back to it. An error aborts the conversation.
+type ToolRunner interface {
+	RunTool(ctx context.Context, session *UserSession, call genai.FunctionCall) (map[string]interface{}, error)
+}
+
+// geminiProvider starts chats on the configured Gemini model
+type geminiProvider struct {
+	client *genai.Client
+}
+
+func (p geminiProvider) StartChat(ctx context.Context, userID string) (ChatSession, string, error) {
+	model, modelName := createNewModel(ctx, p.client, userID)
+	return model.StartChat(), modelName, nil
+}
+
+// NewGeminiProvider connects to Gemini with API_KEY for callers that build their
+// own SessionManager. The returned func closes the client.
+func NewGeminiProvider(ctx context.Context) (Provider, func(), error) {
+	client, err := genai.NewClient(ctx, option.WithAPIKey(os.Getenv("API_KEY")))
+	if err != nil {
+		return nil, nil, err
+	}
+	return geminiProvider{client: client}, func() { client.Close() }, nil
+}
+
+// NewSessionManager returns a session manager that talks to the given provider
+// and runs tools with the given runner, or against the task store when nil.
+// Unlike GetSessionManager it holds no client and doesn't expire sessions.
+func NewSessionManager(provider Provider, tools ToolRunner) *SessionManager {
+	if tools == nil {
+		tools = liveTools{}
+	}
+	return &SessionManager{
+		sessions:    make(map[string]*UserSession),
+		provider:    provider,
+		tools:       tools,
+		cleanupDone: make(chan struct{}),
+	}
+}
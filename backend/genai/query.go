@@ -0,0 +1,99 @@
+package genai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/query"
+	"github.com/Atif-27/ai-task-manager/usage"
+	"github.com/google/generative-ai-go/genai"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/api/option"
+)
+
+var taskQuerySchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"status":         {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString, Enum: []string{"pending", "in_progress", "completed"}}},
+		"priority":       {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString, Enum: []string{"low", "medium", "high"}}},
+		"assignee":       {Type: genai.TypeString, Description: `"me", or the name or email of the person the tasks are assigned to; empty for anyone`},
+		"workspace":      {Type: genai.TypeString, Description: "Name of the workspace or team, empty for all"},
+		"labels":         {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}},
+		"text":           {Type: genai.TypeString, Description: "A word or phrase the title or description must contain"},
+		"overdue":        {Type: genai.TypeBoolean, Description: "Only unfinished tasks past their due date"},
+		"due_after":      {Type: genai.TypeString, Description: "YYYY-MM-DD, inclusive"},
+		"due_before":     {Type: genai.TypeString, Description: "YYYY-MM-DD, inclusive"},
+		"created_after":  {Type: genai.TypeString, Description: "YYYY-MM-DD, inclusive"},
+		"created_before": {Type: genai.TypeString, Description: "YYYY-MM-DD, inclusive"},
+		"sort":           {Type: genai.TypeString, Enum: []string{"due_date", "priority", "created_at", "updated_at"}},
+		"limit":          {Type: genai.TypeInteger},
+	},
+}
+
+// TranslateQuery asks the model to turn a question about tasks into a filter.
+// workspaces are the names of the user's workspaces, so "the backend team" can
+// be matched to one. The result still has to pass query.Validate.
+func TranslateQuery(ctx context.Context, question string, now time.Time, workspaces []string) (models.TaskQuery, error) {
+	if err := usage.Check(ctx); err != nil {
+		return models.TaskQuery{}, err
+	}
+	client, err := genai.NewClient(ctx, option.WithAPIKey(os.Getenv("API_KEY")))
+	if err != nil {
+		return models.TaskQuery{}, fmt.Errorf("failed to create Gemini client: %v", err)
+	}
+	defer client.Close()
+
+	model, config := configuredModel(ctx, client, aiconfig.UseQuery)
+	model.ResponseMIMEType = "application/json"
+	model.ResponseSchema = taskQuerySchema
+
+	prompt := renderPrompt(ctx, config, aiconfig.PromptQuery, map[string]interface{}{
+		"Date":       now.Format("Monday 2006-01-02"),
+		"Question":   question,
+		"Workspaces": strings.Join(workspaces, ", "),
+	})
+
+	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return models.TaskQuery{}, fmt.Errorf("failed to generate AI content: %v", err)
+	}
+	recordUsage(ctx, aiconfig.UseQuery, aiconfig.Settings(config, aiconfig.UseQuery).Name, resp)
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return models.TaskQuery{}, fmt.Errorf("no response from Gemini API")
+	}
+	text, ok := resp.Candidates[0].Content.Parts[0].(genai.Text)
+	if !ok {
+		return models.TaskQuery{}, fmt.Errorf("unexpected response from Gemini API")
+	}
+
+	var q models.TaskQuery
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(text))), &q); err != nil {
+		return models.TaskQuery{}, fmt.Errorf("failed to parse AI response: %v", err)
+	}
+	return q, nil
+}
+
+// QueryTasks answers a question about tasks with real query results: the model
+// only translates the question, the filter runs against the task store
+func QueryTasks(ctx context.Context, userID primitive.ObjectID, question string) (models.TaskQuery, []models.Task, error) {
+	workspaces, err := query.WorkspaceNames(ctx, userID)
+	if err != nil {
+		return models.TaskQuery{}, nil, err
+	}
+	now := time.Now()
+	q, err := TranslateQuery(ctx, question, now, workspaces)
+	if err != nil {
+		return models.TaskQuery{}, nil, err
+	}
+	tasks, err := query.Run(ctx, userID, &q, now)
+	if err != nil {
+		return q, nil, err
+	}
+	return q, tasks, nil
+}
@@ -0,0 +1,104 @@
+package genai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/quickadd"
+	"github.com/Atif-27/ai-task-manager/usage"
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+var quickAddSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"title":     {Type: genai.TypeString},
+		"priority":  {Type: genai.TypeString, Enum: []string{"", "low", "medium", "high"}},
+		"assignees": {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}, Description: "Names after @, without the @"},
+		"labels":    {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}, Description: "Tags after #, without the #"},
+		"due_date":  {Type: genai.TypeString, Description: "YYYY-MM-DD, empty if no date is given"},
+		"due_time":  {Type: genai.TypeString, Description: "HH:MM in 24 hour time, empty if no time is given"},
+	},
+	Required: []string{"title", "priority", "assignees", "labels", "due_date", "due_time"},
+}
+
+// ParseQuickAdd is the fallback for quick-add lines quickadd.Parse finds
+// ambiguous. hint lists what the parser was unsure about.
+func ParseQuickAdd(ctx context.Context, text string, now time.Time, hint []string) (quickadd.Result, error) {
+	if err := usage.Check(ctx); err != nil {
+		return quickadd.Result{}, err
+	}
+	client, err := genai.NewClient(ctx, option.WithAPIKey(os.Getenv("API_KEY")))
+	if err != nil {
+		return quickadd.Result{}, fmt.Errorf("failed to create Gemini client: %v", err)
+	}
+	defer client.Close()
+
+	model, config := configuredModel(ctx, client, aiconfig.UseQuickAdd)
+	model.ResponseMIMEType = "application/json"
+	model.ResponseSchema = quickAddSchema
+
+	prompt := renderPrompt(ctx, config, aiconfig.PromptQuickAdd, map[string]interface{}{
+		"Date": now.Format("Monday 2006-01-02"),
+		"Time": now.Format("15:04"),
+		"Text": text,
+		"Hint": strings.Join(hint, "; "),
+	})
+
+	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return quickadd.Result{}, fmt.Errorf("failed to generate AI content: %v", err)
+	}
+	recordUsage(ctx, aiconfig.UseQuickAdd, aiconfig.Settings(config, aiconfig.UseQuickAdd).Name, resp)
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return quickadd.Result{}, fmt.Errorf("no response from Gemini API")
+	}
+	part, ok := resp.Candidates[0].Content.Parts[0].(genai.Text)
+	if !ok {
+		return quickadd.Result{}, fmt.Errorf("unexpected response from Gemini API")
+	}
+
+	var parsed struct {
+		Title     string   `json:"title"`
+		Priority  string   `json:"priority"`
+		Assignees []string `json:"assignees"`
+		Labels    []string `json:"labels"`
+		DueDate   string   `json:"due_date"`
+		DueTime   string   `json:"due_time"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(part))), &parsed); err != nil {
+		return quickadd.Result{}, fmt.Errorf("failed to parse AI response: %v", err)
+	}
+	result := quickadd.Result{
+		Title:     strings.TrimSpace(parsed.Title),
+		Priority:  models.PriorityType(parsed.Priority),
+		Assignees: parsed.Assignees,
+		Labels:    parsed.Labels,
+	}
+	if result.Title == "" {
+		return quickadd.Result{}, fmt.Errorf("invalid AI response: missing title")
+	}
+	if result.Priority != "" && !result.Priority.ValidatePriority() {
+		result.Priority = ""
+	}
+	if parsed.DueDate != "" {
+		day, err := time.ParseInLocation("2006-01-02", parsed.DueDate, now.Location())
+		if err != nil {
+			return quickadd.Result{}, fmt.Errorf("invalid AI response: bad due date %q", parsed.DueDate)
+		}
+		hour, minute := quickadd.DefaultDueHour, 0
+		if clock, err := time.Parse("15:04", parsed.DueTime); err == nil {
+			hour, minute = clock.Hour(), clock.Minute()
+		}
+		due := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, now.Location())
+		result.DueDate = &due
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package genai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/usage"
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+var standupSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"summary":  {Type: genai.TypeString, Description: "Two or three sentence overview"},
+		"done":     {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}, Description: "What was finished"},
+		"doing":    {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}, Description: "What is in progress"},
+		"blockers": {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}, Description: "What is blocked and why"},
+		"risks":    {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}, Description: "Overdue or at-risk work"},
+	},
+	Required: []string{"summary", "done", "doing", "blockers", "risks"},
+}
+
+// GenerateStandup asks the model for a standup from a plain text description of
+// recent task activity. periodName is e.g. "the last day" or "the last week".
+func GenerateStandup(ctx context.Context, activity string, periodName string) (models.Standup, error) {
+	if err := usage.Check(ctx); err != nil {
+		return models.Standup{}, err
+	}
+	client, err := genai.NewClient(ctx, option.WithAPIKey(os.Getenv("API_KEY")))
+	if err != nil {
+		return models.Standup{}, fmt.Errorf("failed to create Gemini client: %v", err)
+	}
+	defer client.Close()
+
+	model, config := configuredModel(ctx, client, aiconfig.UseStandup)
+	model.ResponseMIMEType = "application/json"
+	model.ResponseSchema = standupSchema
+
+	prompt := renderPrompt(ctx, config, aiconfig.PromptStandup, map[string]interface{}{"Period": periodName, "Activity": activity})
+
+	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return models.Standup{}, fmt.Errorf("failed to generate AI content: %v", err)
+	}
+	recordUsage(ctx, aiconfig.UseStandup, aiconfig.Settings(config, aiconfig.UseStandup).Name, resp)
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return models.Standup{}, fmt.Errorf("no response from Gemini API")
+	}
+	text, ok := resp.Candidates[0].Content.Parts[0].(genai.Text)
+	if !ok {
+		return models.Standup{}, fmt.Errorf("unexpected response from Gemini API")
+	}
+
+	var standup models.Standup
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(text))), &standup); err != nil {
+		return models.Standup{}, fmt.Errorf("failed to parse AI response: %v", err)
+	}
+	return standup, nil
+}
@@ -0,0 +1,48 @@
+package genai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/usage"
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+// Transcribe turns recorded speech into text with the model configured for
+// transcription. mimeType is the audio's format, e.g. audio/wav.
+func Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	if err := usage.Check(ctx); err != nil {
+		return "", err
+	}
+	client, err := genai.NewClient(ctx, option.WithAPIKey(os.Getenv("API_KEY")))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Gemini client: %v", err)
+	}
+	defer client.Close()
+
+	model, config := configuredModel(ctx, client, aiconfig.UseTranscribe)
+	prompt := renderPrompt(ctx, config, aiconfig.PromptTranscribe, nil)
+
+	resp, err := model.GenerateContent(ctx, genai.Blob{MIMEType: mimeType, Data: audio}, genai.Text(prompt))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate AI content: %v", err)
+	}
+	recordUsage(ctx, aiconfig.UseTranscribe, aiconfig.Settings(config, aiconfig.UseTranscribe).Name, resp)
+
+	var transcript strings.Builder
+	for _, cand := range resp.Candidates {
+		if cand.Content == nil {
+			continue
+		}
+		for _, part := range cand.Content.Parts {
+			if text, ok := part.(genai.Text); ok {
+				transcript.WriteString(string(text))
+			}
+		}
+	}
+	return strings.TrimSpace(transcript.String()), nil
+}
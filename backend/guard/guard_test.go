@@ -0,0 +1,103 @@
+package guard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Atif-27/ai-task-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// stubStore replaces the task store with the given tasks and workspace members
+func stubStore(t *testing.T, tasks []models.Task, members map[primitive.ObjectID][]primitive.ObjectID) {
+	t.Helper()
+	previousFind, previousMember := findTask, isMember
+	t.Cleanup(func() { findTask, isMember = previousFind, previousMember })
+
+	findTask = func(ctx context.Context, taskID primitive.ObjectID) (models.Task, error) {
+		for _, task := range tasks {
+			if task.ID == taskID {
+				return task, nil
+			}
+		}
+		return models.Task{}, errors.New("not found")
+	}
+	isMember = func(ctx context.Context, workspaceID, userID primitive.ObjectID) bool {
+		for _, member := range members[workspaceID] {
+			if member == userID {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestCheckTaskAccess(t *testing.T) {
+	user, other := primitive.NewObjectID(), primitive.NewObjectID()
+	workspace, otherWorkspace := primitive.NewObjectID(), primitive.NewObjectID()
+	own := models.Task{ID: primitive.NewObjectID(), AssignedBy: user}
+	assigned := models.Task{ID: primitive.NewObjectID(), AssignedBy: other, AssignedTo: []primitive.ObjectID{user}}
+	foreign := models.Task{ID: primitive.NewObjectID(), AssignedBy: other, AssignedTo: []primitive.ObjectID{other}}
+	shared := models.Task{ID: primitive.NewObjectID(), AssignedBy: other, WorkspaceID: &workspace}
+	foreignShared := models.Task{ID: primitive.NewObjectID(), AssignedBy: other, WorkspaceID: &otherWorkspace}
+	stubStore(t, []models.Task{own, assigned, foreign, shared, foreignShared}, map[primitive.ObjectID][]primitive.ObjectID{
+		workspace:      {user, other},
+		otherWorkspace: {other},
+	})
+
+	rule := ArgRule{TaskIDs: []string{"parent_task_id"}}
+	tests := []struct {
+		name    string
+		taskID  string
+		allowed bool
+	}{
+		{"created by the user", own.ID.Hex(), true},
+		{"assigned to the user", assigned.ID.Hex(), true},
+		{"in the user's workspace", shared.ID.Hex(), true},
+		{"no task ID", "", true},
+		{"another user's task", foreign.ID.Hex(), false},
+		{"another workspace's task", foreignShared.ID.Hex(), false},
+		{"unknown task", primitive.NewObjectID().Hex(), false},
+		{"invalid ID", "task-1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckTaskAccess(context.Background(), rule, map[string]interface{}{"parent_task_id": tt.taskID}, user)
+			if tt.allowed && err != nil {
+				t.Fatalf("got %v, want the call allowed", err)
+			}
+			if !tt.allowed {
+				var refused *Error
+				if !errors.As(err, &refused) {
+					t.Fatalf("got %v, want a refusal", err)
+				}
+			}
+		})
+	}
+}
+
+func TestOtherUsersTaskIsRefusedAndLogged(t *testing.T) {
+	user, other := primitive.NewObjectID(), primitive.NewObjectID()
+	foreign := models.Task{ID: primitive.NewObjectID(), AssignedBy: other}
+	stubStore(t, []models.Task{foreign}, nil)
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	args := map[string]interface{}{"parent_task_id": foreign.ID.Hex()}
+	err := CheckTaskAccess(context.Background(), ArgRule{TaskIDs: []string{"parent_task_id"}}, args, user)
+	if err == nil {
+		t.Fatal("a call on another user's task was allowed")
+	}
+
+	Block(context.Background(), models.GuardEvent{Kind: models.GuardToolAccess, UserID: &user, Tool: "create_task", Args: args, Reason: err.Error()})
+	if !strings.Contains(logged.String(), models.GuardToolAccess) || !strings.Contains(logged.String(), err.Error()) {
+		t.Errorf("the refusal was not logged, got %q", logged.String())
+	}
+}
@@ -0,0 +1,88 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Atif-27/ai-task-manager/models"
+)
+
+type githubIssue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+	Labels  []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Assignees []struct {
+		Login string `json:"login"`
+	} `json:"assignees"`
+	Milestone *struct {
+		DueOn string `json:"due_on"`
+	} `json:"milestone"`
+	PullRequest json.RawMessage `json:"pull_request"`
+}
+
+// ParseGitHub reads issues as returned by GET /repos/{owner}/{repo}/issues, either
+// a single JSON array or several pages concatenated. Pull requests are skipped.
+// Closed issues are completed; open ones take their status from labels such as
+// "in progress". Assignees are matched by login.
+func ParseGitHub(data []byte, mapper *Mapper) ([]Record, error) {
+	var issues []githubIssue
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var page []githubIssue
+		if err := decoder.Decode(&page); err != nil {
+			return nil, fmt.Errorf("invalid GitHub issues export: %v", err)
+		}
+		issues = append(issues, page...)
+	}
+
+	records := make([]Record, 0, len(issues))
+	for i, issue := range issues {
+		if len(issue.PullRequest) > 0 && string(issue.PullRequest) != "null" {
+			continue
+		}
+		labels := make([]string, 0, len(issue.Labels))
+		for _, label := range issue.Labels {
+			labels = append(labels, label.Name)
+		}
+		assignees := make([]string, 0, len(issue.Assignees))
+		for _, assignee := range issue.Assignees {
+			assignees = append(assignees, assignee.Login)
+		}
+
+		var status models.StatusType
+		if strings.EqualFold(issue.State, "closed") {
+			status = mapper.Status(issue.State)
+		} else {
+			status = mapper.StatusFromLabels(labels)
+		}
+
+		due := ""
+		if issue.Milestone != nil {
+			due = issue.Milestone.DueOn
+		}
+		externalID := issue.HTMLURL
+		if externalID == "" {
+			externalID = strconv.Itoa(issue.Number)
+		}
+		records = append(records, Record{
+			Row:         i + 1,
+			Title:       issue.Title,
+			Description: issue.Body,
+			Status:      string(status),
+			Priority:    string(mapper.PriorityFromLabels(labels)),
+			Assignees:   assignees,
+			DueDate:     due,
+			Labels:      labels,
+			ExternalID:  externalID,
+		})
+	}
+	return records, nil
+}
@@ -0,0 +1,404 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Fields that a source column can be mapped onto
+const (
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldStatus      = "status"
+	FieldPriority    = "priority"
+	FieldAssignedTo  = "assigned_to"
+	FieldDueDate     = "due_date"
+	FieldLabels      = "labels"
+)
+
+var Fields = []string{FieldTitle, FieldDescription, FieldStatus, FieldPriority, FieldAssignedTo, FieldDueDate, FieldLabels}
+
+// Mapping maps a task field to the name of the source column or JSON key holding it.
+// Fields missing from the mapping are read from a column of the same name.
+type Mapping map[string]string
+
+func (m Mapping) source(field string) string {
+	if column, ok := m[field]; ok && column != "" {
+		return column
+	}
+	return field
+}
+
+// Validate rejects mappings onto fields that don't exist
+func (m Mapping) Validate() error {
+	for field := range m {
+		known := false
+		for _, f := range Fields {
+			if f == field {
+				known = true
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown field in mapping: %s", field)
+		}
+	}
+	return nil
+}
+
+// Record is one row of an import, still as raw strings
+type Record struct {
+	Row         int      `json:"row"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Priority    string   `json:"priority"`
+	Assignees   []string `json:"assignees"`
+	DueDate     string   `json:"due_date"`
+	Labels      []string `json:"labels"`
+	// Source and ExternalID identify the row in the tool it was exported from, when there is one
+	Source     string `json:"source,omitempty"`
+	ExternalID string `json:"external_id,omitempty"`
+}
+
+// RowError lists everything wrong with a single row
+type RowError struct {
+	Row    int      `json:"row"`
+	Errors []string `json:"errors"`
+}
+
+// Report summarises a validated import
+type Report struct {
+	Total            int        `json:"total"`
+	Valid            int        `json:"valid"`
+	Invalid          int        `json:"invalid"`
+	Errors           []RowError `json:"errors"`
+	UnknownAssignees []string   `json:"unknown_assignees"`
+	// Created and Updated are only filled in for imports keyed on an external ID
+	Created int `json:"created,omitempty"`
+	Updated int `json:"updated,omitempty"`
+}
+
+var dateLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	"2006-01-02T15:04:05.000-0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"01/02/2006",
+	"02/Jan/06 3:04 PM",
+	"02/Jan/06",
+}
+
+// ParseDate accepts RFC 3339 timestamps and the common spreadsheet date formats
+func ParseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", value)
+}
+
+// Validate converts records into tasks, resolving assignees through users.
+// Status and priority are checked with ValidateStatus and ValidatePriority; empty
+// values fall back to pending and low like the create endpoint does. With
+// strictAssignees an unknown assignee makes the row invalid; otherwise it is
+// dropped and only listed in the report.
+func Validate(records []Record, users map[string]primitive.ObjectID, strictAssignees bool) ([]models.Task, Report) {
+	report := Report{Total: len(records), Errors: []RowError{}, UnknownAssignees: []string{}}
+	unknown := map[string]bool{}
+	tasks := make([]models.Task, 0, len(records))
+
+	for _, record := range records {
+		var problems []string
+		task := models.Task{
+			Title:       strings.TrimSpace(record.Title),
+			Description: record.Description,
+			Status:      models.StatusType(strings.ToLower(strings.TrimSpace(record.Status))),
+			Priority:    models.PriorityType(strings.ToLower(strings.TrimSpace(record.Priority))),
+			AssignedTo:  []primitive.ObjectID{},
+			Labels:      cleanLabels(record.Labels),
+
+			ExternalSource: record.Source,
+			ExternalID:     record.ExternalID,
+		}
+		if task.Title == "" {
+			problems = append(problems, "title is required")
+		}
+		if task.ExternalSource != "" && task.ExternalID == "" {
+			problems = append(problems, "issue key or ID is missing")
+		}
+		if task.Status == "" {
+			task.Status = models.PENDING
+		}
+		if !task.Status.ValidateStatus() {
+			problems = append(problems, fmt.Sprintf("invalid status %q", record.Status))
+		}
+		if task.Priority == "" {
+			task.Priority = models.LOW
+		}
+		if !task.Priority.ValidatePriority() {
+			problems = append(problems, fmt.Sprintf("invalid priority %q", record.Priority))
+		}
+		for _, assignee := range record.Assignees {
+			assignee = strings.ToLower(strings.TrimSpace(assignee))
+			if assignee == "" {
+				continue
+			}
+			id, ok := users[assignee]
+			if !ok {
+				if strictAssignees {
+					problems = append(problems, fmt.Sprintf("unknown assignee %q", assignee))
+				}
+				if !unknown[assignee] {
+					unknown[assignee] = true
+					report.UnknownAssignees = append(report.UnknownAssignees, assignee)
+				}
+				continue
+			}
+			if !containsID(task.AssignedTo, id) {
+				task.AssignedTo = append(task.AssignedTo, id)
+			}
+		}
+		if due := strings.TrimSpace(record.DueDate); due != "" {
+			dueDate, err := ParseDate(due)
+			if err != nil {
+				problems = append(problems, err.Error())
+			} else {
+				task.DueDate = &dueDate
+			}
+		}
+
+		if len(problems) > 0 {
+			report.Invalid++
+			report.Errors = append(report.Errors, RowError{Row: record.Row, Errors: problems})
+			continue
+		}
+		report.Valid++
+		tasks = append(tasks, task)
+	}
+	return tasks, report
+}
+
+// LookupUsers returns every user's ID keyed by lower-cased email, email local part,
+// name and name without spaces, so assignees can be matched by whichever the
+// source tool exports.
+func LookupUsers(ctx context.Context) (map[string]primitive.ObjectID, error) {
+	cursor, err := database.GetCollection("user").Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch users: %v", err)
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, fmt.Errorf("failed to parse users: %v", err)
+	}
+	lookup := make(map[string]primitive.ObjectID, len(users)*4)
+	// Names are weaker keys than emails, so they never overwrite an existing entry
+	for _, user := range users {
+		lookup[strings.ToLower(user.Email)] = user.ID
+	}
+	for _, user := range users {
+		name := strings.ToLower(strings.TrimSpace(user.Name))
+		keys := []string{
+			strings.SplitN(strings.ToLower(user.Email), "@", 2)[0],
+			name,
+			strings.ReplaceAll(name, " ", ""),
+		}
+		for _, key := range keys {
+			if _, taken := lookup[key]; key != "" && !taken {
+				lookup[key] = user.ID
+			}
+		}
+	}
+	return lookup, nil
+}
+
+func cleanLabels(labels []string) []string {
+	cleaned := []string{}
+	seen := map[string]bool{}
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label != "" && !seen[strings.ToLower(label)] {
+			seen[strings.ToLower(label)] = true
+			cleaned = append(cleaned, label)
+		}
+	}
+	return cleaned
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply inserts all tasks or none. It uses a transaction when the server supports
+// one (replica sets) and otherwise removes whatever was inserted before a failure.
+func Apply(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	collection := database.GetCollection("task")
+	docs := make([]interface{}, len(tasks))
+	ids := make([]primitive.ObjectID, len(tasks))
+	for i, task := range tasks {
+		docs[i] = task
+		ids[i] = task.ID
+	}
+
+	err := database.WithTransaction(ctx, func(ctx context.Context) error {
+		_, err := collection.InsertMany(ctx, docs)
+		return err
+	})
+	if err == database.ErrNoTransactions {
+		if _, err := collection.InsertMany(ctx, docs); err != nil {
+			if _, cleanupErr := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); cleanupErr != nil {
+				return fmt.Errorf("import failed (%v) and cleanup failed: %v", err, cleanupErr)
+			}
+			return fmt.Errorf("import failed: %v", err)
+		}
+		return nil
+	}
+	return err
+}
+
+// Upsert creates or updates tasks imported from another tool, matching on the
+// source, the external ID and the workspace, so importing the same export twice
+// updates the tasks instead of duplicating them. Without transactions the writes
+// are not atomic, but re-running the import converges to the same result. Tasks
+// in the trash match too, so importing again doesn't bring back deleted tasks.
+func Upsert(ctx context.Context, tasks []models.Task) (created int, updated int, err error) {
+	if len(tasks) == 0 {
+		return 0, 0, nil
+	}
+	collection := database.GetCollection("task")
+	var result *mongo.BulkWriteResult
+	write := func(ctx context.Context) error {
+		stored, err := storedStatuses(ctx, tasks)
+		if err != nil {
+			return err
+		}
+		writes := make([]mongo.WriteModel, len(tasks))
+		for i, task := range tasks {
+			var previous *models.StatusType
+			if status, ok := stored[externalKey(task.ExternalSource, task.ExternalID, task.WorkspaceID)]; ok {
+				previous = &status
+			}
+			writes[i] = mongo.NewUpdateOneModel().
+				SetFilter(externalFilter(task.ExternalSource, task.ExternalID, task.WorkspaceID)).
+				SetUpdate(upsertUpdate(task, previous)).
+				SetUpsert(true)
+		}
+		result, err = collection.BulkWrite(ctx, writes)
+		return err
+	}
+	err = database.WithTransaction(ctx, write)
+	if err == database.ErrNoTransactions {
+		err = write(ctx)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("import failed: %v", err)
+	}
+	return int(result.UpsertedCount), int(result.MatchedCount), nil
+}
+
+// upsertUpdate builds the write for one imported task. previous is the status of
+// the task it updates, nil for a new one. A changed status is added to the
+// history like an edit in the app, which cycle time analytics depend on.
+func upsertUpdate(task models.Task, previous *models.StatusType) bson.M {
+	update := bson.M{
+		"$set": bson.M{
+			"title":       task.Title,
+			"description": task.Description,
+			"status":      task.Status,
+			"priority":    task.Priority,
+			"assigned_to": task.AssignedTo,
+			"due_date":    task.DueDate,
+			"labels":      task.Labels,
+			"updated_at":  task.UpdatedAt,
+		},
+		"$inc": bson.M{"version": 1},
+		"$setOnInsert": bson.M{
+			"_id":         task.ID,
+			"assigned_by": task.AssignedBy,
+			"created_at":  task.CreatedAt,
+		},
+	}
+	switch {
+	case previous == nil:
+		update["$setOnInsert"].(bson.M)["status_history"] = task.StatusHistory
+	case *previous != task.Status:
+		update["$push"] = bson.M{"status_history": models.StatusChange{Status: task.Status, At: task.UpdatedAt, By: task.AssignedBy}}
+	}
+	return update
+}
+
+// storedStatuses returns the current status of the already imported tasks, by
+// externalKey
+func storedStatuses(ctx context.Context, tasks []models.Task) (map[string]models.StatusType, error) {
+	var sources, ids []string
+	for _, task := range tasks {
+		sources = append(sources, task.ExternalSource)
+		ids = append(ids, task.ExternalID)
+	}
+	filter := bson.M{"external_source": bson.M{"$in": sources}, "external_id": bson.M{"$in": ids}}
+	opts := options.Find().SetProjection(bson.M{"external_source": 1, "external_id": 1, "workspace_id": 1, "status": 1})
+	cursor, err := database.GetCollection("task").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var found []models.Task
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	stored := make(map[string]models.StatusType, len(found))
+	for _, task := range found {
+		stored[externalKey(task.ExternalSource, task.ExternalID, task.WorkspaceID)] = task.Status
+	}
+	return stored, nil
+}
+
+func externalKey(source string, externalID string, workspaceID *primitive.ObjectID) string {
+	workspace := ""
+	if workspaceID != nil {
+		workspace = workspaceID.Hex()
+	}
+	return source + "\x00" + externalID + "\x00" + workspace
+}
+
+// ExistingExternalIDs returns which of the given external IDs were already imported
+func ExistingExternalIDs(ctx context.Context, source string, workspaceID *primitive.ObjectID, ids []string) (map[string]bool, error) {
+	filter := externalFilter(source, "", workspaceID)
+	filter["external_id"] = bson.M{"$in": ids}
+	cursor, err := database.GetCollection("task").Find(ctx, filter, options.Find().SetProjection(bson.M{"external_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var found []models.Task
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(found))
+	for _, task := range found {
+		existing[task.ExternalID] = true
+	}
+	return existing, nil
+}
+
+func externalFilter(source string, externalID string, workspaceID *primitive.ObjectID) bson.M {
+	// A nil workspace matches tasks without one
+	return bson.M{"external_source": source, "external_id": externalID, "workspace_id": workspaceID}
+}
@@ -0,0 +1,75 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUpsertUpdateStatusHistory(t *testing.T) {
+	importedBy := primitive.NewObjectID()
+	firstImport := time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+	reImport := firstImport.Add(48 * time.Hour)
+	imported := func(status models.StatusType, at time.Time) models.Task {
+		return models.Task{
+			ID:             primitive.NewObjectID(),
+			Title:          "Fix login",
+			Status:         status,
+			AssignedBy:     importedBy,
+			CreatedAt:      at,
+			UpdatedAt:      at,
+			ExternalSource: SourceGitHub,
+			ExternalID:     "42",
+			StatusHistory:  []models.StatusChange{{Status: status, At: at, By: importedBy}},
+		}
+	}
+	status := func(s models.StatusType) *models.StatusType { return &s }
+
+	tests := []struct {
+		name       string
+		task       models.Task
+		previous   *models.StatusType
+		wantInsert interface{}
+		wantPushed interface{}
+	}{
+		{
+			name:       "first import starts the history",
+			task:       imported(models.PENDING, firstImport),
+			wantInsert: []models.StatusChange{{Status: models.PENDING, At: firstImport, By: importedBy}},
+		},
+		{
+			name:     "re-import with the same status adds nothing",
+			task:     imported(models.PENDING, reImport),
+			previous: status(models.PENDING),
+		},
+		{
+			name:       "re-import with a new status records the change",
+			task:       imported(models.COMPLETED, reImport),
+			previous:   status(models.INPROGRESS),
+			wantPushed: models.StatusChange{Status: models.COMPLETED, At: reImport, By: importedBy},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update := upsertUpdate(tt.task, tt.previous)
+
+			if got := update["$set"].(bson.M)["status"]; got != tt.task.Status {
+				t.Errorf("status set to %v, want %v", got, tt.task.Status)
+			}
+			if got := update["$setOnInsert"].(bson.M)["status_history"]; !reflect.DeepEqual(got, tt.wantInsert) {
+				t.Errorf("history on insert = %v, want %v", got, tt.wantInsert)
+			}
+			var pushed interface{}
+			if push, ok := update["$push"].(bson.M); ok {
+				pushed = push["status_history"]
+			}
+			if !reflect.DeepEqual(pushed, tt.wantPushed) {
+				t.Errorf("pushed history = %v, want %v", pushed, tt.wantPushed)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var htmlTagPattern = regexp.MustCompile(`(?s)<[^>]*>`)
+
+type jiraRSS struct {
+	Items []struct {
+		Key         string `xml:"key"`
+		Summary     string `xml:"summary"`
+		Description string `xml:"description"`
+		Status      string `xml:"status"`
+		Priority    string `xml:"priority"`
+		Assignee    struct {
+			Username string `xml:"username,attr"`
+			Name     string `xml:",chardata"`
+		} `xml:"assignee"`
+		Labels []string `xml:"labels>label"`
+		Due    string   `xml:"due"`
+	} `xml:"channel>item"`
+}
+
+// ParseJira reads either the XML (RSS) or the CSV issue export, telling them apart by content
+func ParseJira(data []byte, mapper *Mapper) ([]Record, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		return parseJiraXML(data, mapper)
+	}
+	return parseJiraCSV(data, mapper)
+}
+
+func parseJiraXML(data []byte, mapper *Mapper) ([]Record, error) {
+	var rss jiraRSS
+	if err := xml.Unmarshal(data, &rss); err != nil {
+		return nil, fmt.Errorf("invalid Jira XML export: %v", err)
+	}
+
+	records := make([]Record, 0, len(rss.Items))
+	for i, item := range rss.Items {
+		var assignees []string
+		if item.Assignee.Username != "" {
+			assignees = append(assignees, item.Assignee.Username)
+		} else if name := strings.TrimSpace(item.Assignee.Name); name != "" && name != "Unassigned" {
+			assignees = append(assignees, name)
+		}
+		records = append(records, Record{
+			Row:         i + 1,
+			Title:       item.Summary,
+			Description: stripHTML(item.Description),
+			Status:      string(mapper.Status(item.Status)),
+			Priority:    string(mapper.Priority(item.Priority)),
+			Assignees:   assignees,
+			DueDate:     strings.TrimSpace(item.Due),
+			Labels:      item.Labels,
+			ExternalID:  item.Key,
+		})
+	}
+	return records, nil
+}
+
+func parseJiraCSV(data []byte, mapper *Mapper) ([]Record, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid Jira CSV export: %v", err)
+	}
+
+	// Jira repeats the Labels column once per label
+	columns := map[string]int{}
+	var labelColumns []int
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if name == "Labels" {
+			labelColumns = append(labelColumns, i)
+		}
+		if _, seen := columns[name]; !seen {
+			columns[name] = i
+		}
+	}
+	if _, ok := columns["Summary"]; !ok {
+		return nil, fmt.Errorf("invalid Jira CSV export: no Summary column")
+	}
+
+	var records []Record
+	for row := 2; ; row++ {
+		values, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid Jira CSV on line %d: %v", row, err)
+		}
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(values) {
+				return strings.TrimSpace(values[i])
+			}
+			return ""
+		}
+		var labels []string
+		for _, i := range labelColumns {
+			if i < len(values) && strings.TrimSpace(values[i]) != "" {
+				labels = append(labels, strings.TrimSpace(values[i]))
+			}
+		}
+		var assignees []string
+		if assignee := get("Assignee"); assignee != "" {
+			assignees = append(assignees, assignee)
+		}
+		records = append(records, Record{
+			Row:         row,
+			Title:       get("Summary"),
+			Description: get("Description"),
+			Status:      string(mapper.Status(get("Status"))),
+			Priority:    string(mapper.Priority(get("Priority"))),
+			Assignees:   assignees,
+			DueDate:     get("Due Date"),
+			Labels:      labels,
+			ExternalID:  get("Issue key"),
+		})
+	}
+	return records, nil
+}
+
+// stripHTML turns Jira's rendered HTML description back into plain text
+func stripHTML(s string) string {
+	s = strings.NewReplacer("<br/>", "\n", "<br>", "\n", "</p>", "\n\n", "</li>", "\n").Replace(s)
+	return strings.TrimSpace(html.UnescapeString(htmlTagPattern.ReplaceAllString(s, "")))
+}
@@ -0,0 +1,154 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxRows caps a single import so a huge file can't tie up the server
+const MaxRows = 5000
+
+// ParseCSV reads a CSV file with a header row. Assignees are email addresses
+// separated by ";" or ",".
+func ParseCSV(r io.Reader, mapping Mapping) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("CSV file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheets often save UTF-8 CSVs with a byte order mark
+		columns[strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")] = i
+	}
+	if _, ok := columns[mapping.source(FieldTitle)]; !ok {
+		return nil, fmt.Errorf("CSV has no %q column for the task title", mapping.source(FieldTitle))
+	}
+
+	var records []Record
+	for row := 2; ; row++ {
+		values, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV on line %d: %v", row, err)
+		}
+		if len(records) >= MaxRows {
+			return nil, fmt.Errorf("imports are limited to %d rows", MaxRows)
+		}
+		get := func(field string) string {
+			if i, ok := columns[mapping.source(field)]; ok && i < len(values) {
+				return values[i]
+			}
+			return ""
+		}
+		records = append(records, Record{
+			Row:         row,
+			Title:       get(FieldTitle),
+			Description: get(FieldDescription),
+			Status:      get(FieldStatus),
+			Priority:    get(FieldPriority),
+			Assignees:   splitList(get(FieldAssignedTo)),
+			DueDate:     get(FieldDueDate),
+			Labels:      splitList(get(FieldLabels)),
+		})
+	}
+	return records, nil
+}
+
+// ParseJSON reads either a JSON array of objects or newline-delimited objects.
+// assigned_to and labels may be lists or a single separated string.
+func ParseJSON(data []byte, mapping Mapping) ([]Record, error) {
+	var objects []map[string]interface{}
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &objects); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var object map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &object); err != nil {
+				return nil, fmt.Errorf("invalid JSON on line %d: %v", line, err)
+			}
+			objects = append(objects, object)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+	}
+	if len(objects) > MaxRows {
+		return nil, fmt.Errorf("imports are limited to %d rows", MaxRows)
+	}
+
+	records := make([]Record, 0, len(objects))
+	for i, object := range objects {
+		get := func(field string) string {
+			return stringValue(object[mapping.source(field)])
+		}
+		getList := func(field string) []string {
+			list, ok := object[mapping.source(field)].([]interface{})
+			if !ok {
+				return splitList(get(field))
+			}
+			values := make([]string, 0, len(list))
+			for _, item := range list {
+				values = append(values, stringValue(item))
+			}
+			return values
+		}
+		records = append(records, Record{
+			Row:         i + 1,
+			Title:       get(FieldTitle),
+			Description: get(FieldDescription),
+			Status:      get(FieldStatus),
+			Priority:    get(FieldPriority),
+			Assignees:   getList(FieldAssignedTo),
+			DueDate:     get(FieldDueDate),
+			Labels:      getList(FieldLabels),
+		})
+	}
+	return records, nil
+}
+
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64, bool:
+		return fmt.Sprint(v)
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
+
+func splitList(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == ',' })
+	list := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			list = append(list, field)
+		}
+	}
+	return list
+}
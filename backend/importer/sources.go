@@ -0,0 +1,180 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Atif-27/ai-task-manager/models"
+)
+
+// Supported export formats of other tools
+const (
+	SourceTrello = "trello"
+	SourceJira   = "jira"
+	SourceGitHub = "github"
+)
+
+// Overrides replace the built-in status and priority translation for specific
+// source values, e.g. {"QA": "in_progress"}. Keys are matched case-insensitively.
+type Overrides struct {
+	Status   map[string]string `json:"status"`
+	Priority map[string]string `json:"priority"`
+}
+
+// Validate checks every override targets a real status or priority
+func (o Overrides) Validate() error {
+	for from, to := range o.Status {
+		if !models.StatusType(to).ValidateStatus() {
+			return fmt.Errorf("invalid status %q for %q", to, from)
+		}
+	}
+	for from, to := range o.Priority {
+		if !models.PriorityType(to).ValidatePriority() {
+			return fmt.Errorf("invalid priority %q for %q", to, from)
+		}
+	}
+	return nil
+}
+
+// Preview shows how each distinct source status and priority was translated
+type Preview struct {
+	Statuses   map[string]models.StatusType   `json:"statuses"`
+	Priorities map[string]models.PriorityType `json:"priorities"`
+}
+
+// Mapper translates source values and records what it did for the preview
+type Mapper struct {
+	overrides Overrides
+	preview   Preview
+}
+
+func NewMapper(overrides Overrides) *Mapper {
+	return &Mapper{
+		overrides: overrides,
+		preview: Preview{
+			Statuses:   map[string]models.StatusType{},
+			Priorities: map[string]models.PriorityType{},
+		},
+	}
+}
+
+func (m *Mapper) Preview() Preview {
+	return m.preview
+}
+
+// Status maps a workflow state name onto pending, in_progress or completed
+func (m *Mapper) Status(source string) models.StatusType {
+	status, ok := m.matchStatus(source)
+	if !ok {
+		status = models.PENDING
+	}
+	if source != "" {
+		m.preview.Statuses[source] = status
+	}
+	return status
+}
+
+// StatusFromLabels returns the first status expressed by a label, or pending
+func (m *Mapper) StatusFromLabels(labels []string) models.StatusType {
+	for _, label := range labels {
+		if status, ok := m.matchStatus(label); ok {
+			m.preview.Statuses[label] = status
+			return status
+		}
+	}
+	return models.PENDING
+}
+
+func (m *Mapper) matchStatus(source string) (models.StatusType, bool) {
+	if override, ok := lookupFold(m.overrides.Status, source); ok {
+		return models.StatusType(override), true
+	}
+	name := strings.ToLower(source)
+	switch {
+	case containsAny(name, "done", "complete", "closed", "resolved", "fixed", "shipped", "released"):
+		return models.COMPLETED, true
+	case containsAny(name, "progress", "doing", "review", "testing", "started", "active", "wip"):
+		return models.INPROGRESS, true
+	}
+	return "", false
+}
+
+// Priority maps a priority name or label onto low, medium or high. It returns an
+// empty priority when the value says nothing about priority.
+func (m *Mapper) Priority(source string) models.PriorityType {
+	var priority models.PriorityType
+	if override, ok := lookupFold(m.overrides.Priority, source); ok {
+		priority = models.PriorityType(override)
+	} else {
+		name := strings.ToLower(strings.TrimSpace(source))
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "priority:"), "priority/")
+		name = strings.TrimSpace(name)
+		switch name {
+		case "highest", "high", "blocker", "critical", "urgent", "p0", "p1":
+			priority = models.HIGH
+		case "medium", "normal", "major", "p2":
+			priority = models.MEDIUM
+		case "low", "lowest", "minor", "trivial", "p3", "p4":
+			priority = models.LOW
+		}
+	}
+	if priority != "" {
+		m.preview.Priorities[source] = priority
+	}
+	return priority
+}
+
+// PriorityFromLabels returns the first priority expressed by a label
+func (m *Mapper) PriorityFromLabels(labels []string) models.PriorityType {
+	for _, label := range labels {
+		if priority := m.Priority(label); priority != "" {
+			return priority
+		}
+	}
+	return ""
+}
+
+func lookupFold(values map[string]string, key string) (string, bool) {
+	for k, v := range values {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func containsAny(s string, words ...string) bool {
+	for _, word := range words {
+		if strings.Contains(s, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSource reads an export file of one of the supported tools
+func ParseSource(source string, data []byte, overrides Overrides) ([]Record, Preview, error) {
+	mapper := NewMapper(overrides)
+	var records []Record
+	var err error
+	switch source {
+	case SourceTrello:
+		records, err = ParseTrello(data, mapper)
+	case SourceJira:
+		records, err = ParseJira(data, mapper)
+	case SourceGitHub:
+		records, err = ParseGitHub(data, mapper)
+	default:
+		return nil, Preview{}, fmt.Errorf("unknown source %q, expected trello, jira or github", source)
+	}
+	if err != nil {
+		return nil, Preview{}, err
+	}
+	if len(records) > MaxRows {
+		return nil, Preview{}, fmt.Errorf("imports are limited to %d rows", MaxRows)
+	}
+	for i := range records {
+		records[i].Source = source
+	}
+	return records, mapper.Preview(), nil
+}
@@ -0,0 +1,92 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Atif-27/ai-task-manager/models"
+)
+
+type trelloBoard struct {
+	Lists []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"lists"`
+	Members []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		FullName string `json:"fullName"`
+	} `json:"members"`
+	Cards []struct {
+		ID          string   `json:"id"`
+		Name        string   `json:"name"`
+		Desc        string   `json:"desc"`
+		IDList      string   `json:"idList"`
+		IDMembers   []string `json:"idMembers"`
+		Due         string   `json:"due"`
+		DueComplete bool     `json:"dueComplete"`
+		Closed      bool     `json:"closed"`
+		Labels      []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+}
+
+// ParseTrello reads a board exported with "Export as JSON". The card's list gives
+// its status, labels give its priority, and archived cards count as completed.
+// Members are matched by username.
+func ParseTrello(data []byte, mapper *Mapper) ([]Record, error) {
+	var board trelloBoard
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, fmt.Errorf("invalid Trello export: %v", err)
+	}
+	if board.Cards == nil {
+		return nil, fmt.Errorf("invalid Trello export: no cards found")
+	}
+
+	lists := make(map[string]string, len(board.Lists))
+	for _, list := range board.Lists {
+		lists[list.ID] = list.Name
+	}
+	members := make(map[string]string, len(board.Members))
+	for _, member := range board.Members {
+		members[member.ID] = member.Username
+	}
+
+	records := make([]Record, 0, len(board.Cards))
+	for i, card := range board.Cards {
+		labels := make([]string, 0, len(card.Labels))
+		for _, label := range card.Labels {
+			// Trello labels can be colour-only
+			if label.Name != "" {
+				labels = append(labels, label.Name)
+			} else if label.Color != "" {
+				labels = append(labels, label.Color)
+			}
+		}
+		assignees := make([]string, 0, len(card.IDMembers))
+		for _, id := range card.IDMembers {
+			if username, ok := members[id]; ok {
+				assignees = append(assignees, username)
+			}
+		}
+
+		status := mapper.Status(lists[card.IDList])
+		if card.Closed || card.DueComplete {
+			status = models.COMPLETED
+		}
+		records = append(records, Record{
+			Row:         i + 1,
+			Title:       card.Name,
+			Description: card.Desc,
+			Status:      string(status),
+			Priority:    string(mapper.PriorityFromLabels(labels)),
+			Assignees:   assignees,
+			DueDate:     card.Due,
+			Labels:      labels,
+			ExternalID:  card.ID,
+		})
+	}
+	return records, nil
+}
@@ -0,0 +1,148 @@
+package inbound
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlTagPattern    = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// FromEmail parses a raw RFC 5322 message. The subject becomes the title, the
+// plain text body (or the HTML body with tags stripped) the description, and
+// every part with a filename an attachment.
+func FromEmail(raw []byte) (Draft, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return Draft{}, fmt.Errorf("invalid email: %v", err)
+	}
+
+	decoder := new(mime.WordDecoder)
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	var body emailBody
+	if err := body.walk(msg.Header, msg.Body); err != nil {
+		return Draft{}, err
+	}
+
+	description := body.text
+	if description == "" && body.html != "" {
+		description = htmlTagPattern.ReplaceAllString(body.html, "")
+	}
+	description = blankLinesPattern.ReplaceAllString(strings.TrimSpace(strings.ReplaceAll(description, "\r\n", "\n")), "\n\n")
+	if from := msg.Header.Get("From"); from != "" {
+		if decoded, err := decoder.DecodeHeader(from); err == nil {
+			from = decoded
+		}
+		description = strings.TrimSpace("From: " + from + "\n\n" + description)
+	}
+
+	return Draft{
+		Title:       strings.TrimSpace(subject),
+		Description: description,
+		Attachments: body.attachments,
+	}, nil
+}
+
+// header is the subset of a MIME header the walker needs
+type header interface {
+	Get(key string) string
+}
+
+type emailBody struct {
+	text        string
+	html        string
+	attachments []Attachment
+}
+
+// walk descends into multipart bodies, keeping the first text and html parts
+func (b *emailBody) walk(h header, r io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("invalid multipart email: %v", err)
+			}
+			if err := b.walk(part.Header, part); err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := io.ReadAll(decodeTransfer(h.Get("Content-Transfer-Encoding"), r))
+	if err != nil {
+		return fmt.Errorf("failed to read email part: %v", err)
+	}
+
+	_, dispositionParams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	switch {
+	case filename != "" || strings.HasPrefix(h.Get("Content-Disposition"), "attachment"):
+		if filename == "" {
+			filename = "attachment"
+		}
+		b.attachments = append(b.attachments, Attachment{Filename: filename, ContentType: mediaType, Data: content})
+	case mediaType == "text/plain" && b.text == "":
+		b.text = string(content)
+	case mediaType == "text/html" && b.html == "":
+		b.html = string(content)
+	}
+	return nil
+}
+
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &newlineStripper{r: r})
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
+
+// newlineStripper removes line breaks so wrapped base64 can be decoded
+type newlineStripper struct {
+	r io.Reader
+}
+
+func (n *newlineStripper) Read(p []byte) (int, error) {
+	for {
+		count, err := n.r.Read(p)
+		kept := 0
+		for _, c := range p[:count] {
+			if c != '\r' && c != '\n' {
+				p[kept] = c
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
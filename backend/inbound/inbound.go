@@ -0,0 +1,102 @@
+package inbound
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/Atif-27/ai-task-manager/models"
+)
+
+// Draft is the task data extracted from an inbound payload before it is created
+type Draft struct {
+	Title       string
+	Description string
+	Priority    models.PriorityType
+	Attachments []Attachment
+}
+
+// Attachment is a file found in an inbound email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// defaultTemplate is used when a token has no template configured
+var defaultTemplate = models.InboundTemplate{
+	Title:       "{{.title}}",
+	Description: "{{.description}}",
+	Priority:    "{{.priority}}",
+}
+
+// FromJSON renders the token's template against a JSON payload
+func FromJSON(body []byte, tmpl models.InboundTemplate) (Draft, error) {
+	var payload interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return Draft{}, fmt.Errorf("invalid JSON payload: %v", err)
+	}
+
+	if tmpl.Title == "" {
+		tmpl.Title = defaultTemplate.Title
+	}
+	if tmpl.Description == "" {
+		tmpl.Description = defaultTemplate.Description
+	}
+	if tmpl.Priority == "" {
+		tmpl.Priority = defaultTemplate.Priority
+	}
+
+	title, err := render("title", tmpl.Title, payload)
+	if err != nil {
+		return Draft{}, err
+	}
+	description, err := render("description", tmpl.Description, payload)
+	if err != nil {
+		return Draft{}, err
+	}
+	priority, err := render("priority", tmpl.Priority, payload)
+	if err != nil {
+		return Draft{}, err
+	}
+
+	return Draft{
+		Title:       title,
+		Description: description,
+		Priority:    MapPriority(priority, tmpl.PriorityMap),
+	}, nil
+}
+
+// MapPriority translates a source priority through priorityMap. Values that are
+// neither mapped nor a valid priority give an empty priority.
+func MapPriority(value string, priorityMap map[string]string) models.PriorityType {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for from, to := range priorityMap {
+		if strings.ToLower(from) == value {
+			value = strings.ToLower(to)
+			break
+		}
+	}
+	priority := models.PriorityType(value)
+	if !priority.ValidatePriority() {
+		return ""
+	}
+	return priority
+}
+
+func render(name string, text string, payload interface{}) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %v", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, payload); err != nil {
+		return "", fmt.Errorf("failed to render %s: %v", name, err)
+	}
+	// Missing keys in a map render as "<no value>"
+	return strings.TrimSpace(strings.ReplaceAll(buf.String(), "<no value>", "")), nil
+}
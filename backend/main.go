@@ -1,12 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
+	"github.com/Atif-27/ai-task-manager/aiconfig"
 	"github.com/Atif-27/ai-task-manager/api"
 	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/genai"
 	"github.com/Atif-27/ai-task-manager/middleware"
+	"github.com/Atif-27/ai-task-manager/notification"
+	"github.com/Atif-27/ai-task-manager/summary"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"github.com/Atif-27/ai-task-manager/webhook"
 	"github.com/Atif-27/ai-task-manager/ws"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -16,21 +23,48 @@ import (
 
 func main() {
 	_ = godotenv.Load()
+	if err := aiconfig.Load(); err != nil {
+		log.Fatalf("AI config: %v", err)
+	}
 	database.ConnectDB()
+	database.EnsureIndexes(context.Background())
+	notification.Start(context.Background())
+	webhook.Start(context.Background())
+	summary.Start(context.Background())
+	trash.Start(context.Background())
+	// Tasks created in the chat are announced like the API's own
+	genai.TaskCreated = api.AnnounceTaskCreated
 
 	var (
-		app = fiber.New()
+		// Bodies are streamed so api.LimitBody can allow attachment uploads more
+		// than the default limit without raising it for every route
+		app = fiber.New(fiber.Config{StreamRequestBody: true, DisablePreParseMultipartForm: true})
 		//Handlers
 		userHandler = api.MakeUserHandler()
 		taskHandler = api.MakeTaskHandler()
+		notificationHandler = api.MakeNotificationHandler()
+		workspaceHandler = api.MakeWorkspaceHandler()
+		webhookHandler = api.MakeWebhookHandler()
+		inboundHandler = api.MakeInboundHandler(taskHandler)
+		calendarHandler = api.MakeCalendarHandler()
+		timeHandler = api.MakeTimeHandler()
+		analyticsHandler = api.MakeAnalyticsHandler()
+		aiHandler = api.MakeAIHandler()
+		aiConfigHandler = api.MakeAIConfigHandler()
+		usageHandler = api.MakeUsageHandler()
+		attachmentHandler = api.MakeAttachmentHandler()
+		guardHandler = api.MakeGuardHandler()
+		activityHandler = api.MakeActivityHandler()
 	)
 	origin:= os.Getenv("ORIGIN_URL")
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     origin,
-		AllowHeaders:     "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With",
+		AllowHeaders:     "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, If-Match",
+		ExposeHeaders:    "ETag",
 		AllowMethods:     "GET, POST, PUT, DELETE, OPTIONS",
 		AllowCredentials: true,
 	}))
+	app.Use(api.LimitBody)
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.SendString("Health check")
 	})
@@ -38,13 +72,70 @@ func main() {
 	apiV1.Post("/register", userHandler.Register)
 	apiV1.Post("/login", userHandler.Login)
 	apiV1.Get("/users", userHandler.GetAllUsers)
+	apiV1.Get("/users/me/notifications", middleware.AuthMiddleware, userHandler.GetNotificationPreferences)
+	apiV1.Put("/users/me/notifications", middleware.AuthMiddleware, userHandler.UpdateNotificationPreferences)
+	apiV1.Get("/users/me/calendar", middleware.AuthMiddleware, calendarHandler.GetCalendar)
+	apiV1.Post("/users/me/calendar/rotate", middleware.AuthMiddleware, calendarHandler.RotateCalendar)
+	apiV1.Get("/calendar/:token.ics", calendarHandler.Feed)
 
 	apiV1.Post("/tasks", middleware.AuthMiddleware, taskHandler.CreateTask)
 	apiV1.Delete("/tasks/:id", middleware.AuthMiddleware, taskHandler.DeleteTask)
+	apiV1.Post("/tasks/:id/restore", middleware.AuthMiddleware, taskHandler.RestoreTask)
+	apiV1.Get("/trash", middleware.AuthMiddleware, taskHandler.GetTrash)
+	apiV1.Get("/activity", middleware.AuthMiddleware, activityHandler.GetActivity)
 	apiV1.Put("/tasks/:id", middleware.AuthMiddleware, taskHandler.UpdateTask)
 	apiV1.Get("/tasks", middleware.AuthMiddleware, taskHandler.GetAllTasks)
 	apiV1.Get("/tasks/me", middleware.AuthMiddleware, taskHandler.GetUserTasks)
+	apiV1.Get("/tasks/export", middleware.AuthMiddleware, taskHandler.ExportTasks)
+	apiV1.Post("/tasks/import", middleware.AuthMiddleware, taskHandler.ImportTasks)
+	apiV1.Post("/tasks/bulk", middleware.AuthMiddleware, taskHandler.BulkTasks)
+	apiV1.Post("/tasks/import/:source", middleware.AuthMiddleware, taskHandler.ImportFromSource)
 	apiV1.Get("/tasks/:id", taskHandler.GetTaskByID)
+	apiV1.Post("/tasks/:id/timer/start", middleware.AuthMiddleware, timeHandler.StartTimer)
+	apiV1.Post("/tasks/:id/timer/stop", middleware.AuthMiddleware, timeHandler.StopTimer)
+	apiV1.Get("/tasks/:id/time-entries", middleware.AuthMiddleware, timeHandler.GetTaskEntries)
+	apiV1.Post("/tasks/:id/time-entries", middleware.AuthMiddleware, timeHandler.AddEntry)
+	apiV1.Delete("/tasks/:id/time-entries/:entryId", middleware.AuthMiddleware, timeHandler.DeleteEntry)
+	apiV1.Post("/tasks/:id/attachments", middleware.AuthMiddleware, attachmentHandler.Upload)
+	apiV1.Get("/tasks/:id/attachments", middleware.AuthMiddleware, attachmentHandler.GetAttachments)
+	apiV1.Get("/tasks/:id/attachments/:attachmentId", middleware.AuthMiddleware, attachmentHandler.Download)
+	apiV1.Delete("/tasks/:id/attachments/:attachmentId", middleware.AuthMiddleware, attachmentHandler.DeleteAttachment)
+	apiV1.Get("/users/me/timer", middleware.AuthMiddleware, timeHandler.GetRunningTimer)
+	apiV1.Get("/reports/time", middleware.AuthMiddleware, timeHandler.TimeReport)
+	apiV1.Get("/analytics", middleware.AuthMiddleware, analyticsHandler.GetAnalytics)
+	apiV1.Post("/ai/summary", middleware.AuthMiddleware, aiHandler.Summary)
+	apiV1.Post("/ai/assign-suggestions", middleware.AuthMiddleware, aiHandler.AssignSuggestions)
+	apiV1.Post("/ai/breakdown", middleware.AuthMiddleware, aiHandler.Breakdown)
+	apiV1.Post("/ai/breakdown/commit", middleware.AuthMiddleware, aiHandler.CommitBreakdown)
+	apiV1.Post("/ai/query", middleware.AuthMiddleware, aiHandler.Query)
+	apiV1.Post("/ai/voice", middleware.AuthMiddleware, aiHandler.Voice)
+	apiV1.Post("/ai/extract-tasks", middleware.AuthMiddleware, aiHandler.ExtractTasks)
+	apiV1.Get("/ai/usage", middleware.AuthMiddleware, usageHandler.Report)
+	apiV1.Get("/ai/guard-events", middleware.AuthMiddleware, guardHandler.GetEvents)
+	apiV1.Get("/ai/config", middleware.AuthMiddleware, aiConfigHandler.GetConfig)
+	apiV1.Get("/ai/config/versions", middleware.AuthMiddleware, aiConfigHandler.GetVersions)
+	apiV1.Post("/ai/config/versions", middleware.AuthMiddleware, aiConfigHandler.CreateVersion)
+	apiV1.Post("/ai/config/versions/:version/activate", middleware.AuthMiddleware, aiConfigHandler.ActivateVersion)
+
+	apiV1.Get("/notifications", middleware.AuthMiddleware, notificationHandler.GetNotifications)
+	apiV1.Post("/notifications/read-all", middleware.AuthMiddleware, notificationHandler.MarkAllRead)
+	apiV1.Post("/notifications/:id/read", middleware.AuthMiddleware, notificationHandler.MarkRead)
+
+	apiV1.Post("/workspaces", middleware.AuthMiddleware, workspaceHandler.CreateWorkspace)
+	apiV1.Get("/workspaces", middleware.AuthMiddleware, workspaceHandler.GetUserWorkspaces)
+	apiV1.Post("/workspaces/:id/members", middleware.AuthMiddleware, workspaceHandler.AddMember)
+
+	apiV1.Post("/workspaces/:id/webhooks", middleware.AuthMiddleware, webhookHandler.CreateWebhook)
+	apiV1.Get("/workspaces/:id/webhooks", middleware.AuthMiddleware, webhookHandler.GetWebhooks)
+	apiV1.Delete("/workspaces/:id/webhooks/:webhookId", middleware.AuthMiddleware, webhookHandler.DeleteWebhook)
+	apiV1.Get("/workspaces/:id/webhooks/:webhookId/deliveries", middleware.AuthMiddleware, webhookHandler.GetDeliveries)
+	apiV1.Post("/workspaces/:id/webhooks/:webhookId/deliveries/:deliveryId/redeliver", middleware.AuthMiddleware, webhookHandler.Redeliver)
+
+	apiV1.Post("/workspaces/:id/inbound-tokens", middleware.AuthMiddleware, inboundHandler.CreateToken)
+	apiV1.Get("/workspaces/:id/inbound-tokens", middleware.AuthMiddleware, inboundHandler.GetTokens)
+	apiV1.Delete("/workspaces/:id/inbound-tokens/:tokenId", middleware.AuthMiddleware, inboundHandler.DeleteToken)
+	apiV1.Post("/inbound/:token", inboundHandler.Receive)
+
 	apiV1.Post("/check",middleware.AuthMiddleware,func(c *fiber.Ctx) error {
 		return c.SendString("Auth Working")
 	})
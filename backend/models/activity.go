@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actions recorded in the activity log
+const (
+	ActivityTasksBulkUpdated = "tasks_bulk_updated"
+)
+
+// Activity is an entry of the activity log: who changed which tasks, and how
+type Activity struct {
+	ID           primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Action       string                 `bson:"action" json:"action"`
+	ActorID      primitive.ObjectID     `bson:"actor_id" json:"actor_id"`
+	WorkspaceIDs []primitive.ObjectID   `bson:"workspace_ids,omitempty" json:"workspace_ids,omitempty"`
+	TaskIDs      []primitive.ObjectID   `bson:"task_ids" json:"task_ids"`
+	Details      map[string]interface{} `bson:"details,omitempty" json:"details,omitempty"`
+	CreatedAt    time.Time              `bson:"created_at" json:"created_at"`
+}
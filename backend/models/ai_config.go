@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AIModelSettings configure the model used for one kind of AI call. Empty fields
+// inherit from the "default" entry and then from the built-in settings.
+type AIModelSettings struct {
+	Name            string   `bson:"name,omitempty" json:"name,omitempty"`
+	Temperature     *float32 `bson:"temperature,omitempty" json:"temperature,omitempty"`
+	MaxOutputTokens *int32   `bson:"max_output_tokens,omitempty" json:"max_output_tokens,omitempty"`
+	// Safety maps a harm category (harassment, hate_speech, sexually_explicit,
+	// dangerous_content) to a threshold (block_none, block_only_high,
+	// block_medium_and_above, block_low_and_above)
+	Safety map[string]string `bson:"safety,omitempty" json:"safety,omitempty"`
+}
+
+// AIModelCost is the price of a model in US dollars per million tokens
+type AIModelCost struct {
+	InputPerMillion  float64 `bson:"input_per_million" json:"input_per_million"`
+	OutputPerMillion float64 `bson:"output_per_million" json:"output_per_million"`
+}
+
+// AIQuotas limit the tokens used per day and per calendar month (UTC), by each
+// user and by each workspace. Nil inherits the limit, 0 is unlimited.
+type AIQuotas struct {
+	UserDaily        *int64 `bson:"user_daily,omitempty" json:"user_daily,omitempty"`
+	UserMonthly      *int64 `bson:"user_monthly,omitempty" json:"user_monthly,omitempty"`
+	WorkspaceDaily   *int64 `bson:"workspace_daily,omitempty" json:"workspace_daily,omitempty"`
+	WorkspaceMonthly *int64 `bson:"workspace_monthly,omitempty" json:"workspace_monthly,omitempty"`
+}
+
+// AIConfig holds model settings per use (chat, suggestion, standup, breakdown,
+// query, quick_add or default), prompt templates by name, chat tool descriptions
+// by tool name, costs by model name and token quotas. A config only needs the
+// entries it overrides.
+type AIConfig struct {
+	Models  map[string]AIModelSettings `bson:"models,omitempty" json:"models,omitempty"`
+	Prompts map[string]string          `bson:"prompts,omitempty" json:"prompts,omitempty"`
+	Tools   map[string]string          `bson:"tools,omitempty" json:"tools,omitempty"`
+	Costs   map[string]AIModelCost     `bson:"costs,omitempty" json:"costs,omitempty"`
+	Quotas  AIQuotas                   `bson:"quotas,omitempty" json:"quotas,omitempty"`
+}
+
+// AIConfigVersion is one saved revision of the global (WorkspaceID nil) or a
+// workspace's AI config. Only the active version of each is applied.
+type AIConfigVersion struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	WorkspaceID *primitive.ObjectID `bson:"workspace_id" json:"workspace_id,omitempty"`
+	Version     int                 `bson:"version" json:"version"`
+	Config      AIConfig            `bson:"config" json:"config"`
+	Note        string              `bson:"note,omitempty" json:"note,omitempty"`
+	Active      bool                `bson:"active" json:"active"`
+	CreatedBy   primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+}
+
+type AIConfigVersionRequest struct {
+	Config      AIConfig            `json:"config"`
+	Note        string              `json:"note"`
+	WorkspaceID *primitive.ObjectID `json:"workspace_id,omitempty"`
+}
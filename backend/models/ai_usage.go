@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AIUsage records the tokens of one model call. CostUSD is priced with the cost
+// table in force when the call was made.
+type AIUsage struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID       *primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	WorkspaceID  *primitive.ObjectID `bson:"workspace_id,omitempty" json:"workspace_id,omitempty"`
+	Use          string              `bson:"use" json:"use"`
+	Model        string              `bson:"model" json:"model"`
+	PromptTokens int64               `bson:"prompt_tokens" json:"prompt_tokens"`
+	OutputTokens int64               `bson:"output_tokens" json:"output_tokens"`
+	TotalTokens  int64               `bson:"total_tokens" json:"total_tokens"`
+	CostUSD      float64             `bson:"cost_usd" json:"cost_usd"`
+	CreatedAt    time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// AIUsageTotal is one row of the usage report. Name is the user's or
+// workspace's name when grouping by them.
+type AIUsageTotal struct {
+	Key          string  `bson:"_id" json:"key"`
+	Name         string  `bson:"-" json:"name,omitempty"`
+	Requests     int64   `bson:"requests" json:"requests"`
+	PromptTokens int64   `bson:"prompt_tokens" json:"prompt_tokens"`
+	OutputTokens int64   `bson:"output_tokens" json:"output_tokens"`
+	TotalTokens  int64   `bson:"total_tokens" json:"total_tokens"`
+	CostUSD      float64 `bson:"cost_usd" json:"cost_usd"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Attachment is a file on a task. The content lives in the blob store under Key.
+type Attachment struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TaskID      primitive.ObjectID `bson:"task_id" json:"task_id"`
+	Filename    string             `bson:"filename" json:"filename"`
+	ContentType string             `bson:"content_type" json:"content_type"`
+	Size        int64              `bson:"size" json:"size"`
+	Key         string             `bson:"key" json:"-"`
+	UploadedBy  primitive.ObjectID `bson:"uploaded_by" json:"uploaded_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// BreakdownItem is one task of a breakdown draft. Items refer to each other by
+// Key, which only has to be unique within the draft: Parent makes the item a
+// subtask, DependsOn lists items that must be finished first.
+type BreakdownItem struct {
+	Key             string       `json:"key"`
+	Parent          string       `json:"parent,omitempty"`
+	Title           string       `json:"title"`
+	Description     string       `json:"description"`
+	Priority        PriorityType `json:"priority"`
+	EstimateMinutes int          `json:"estimate_minutes"`
+	DependsOn       []string     `json:"depends_on"`
+}
+
+// Breakdown is an editable draft of the tasks needed to reach a goal
+type Breakdown struct {
+	Goal  string          `json:"goal"`
+	Items []BreakdownItem `json:"items"`
+}
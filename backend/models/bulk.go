@@ -0,0 +1,44 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Operations a bulk request can apply to tasks
+const (
+	BulkSetStatus    = "set_status"
+	BulkSetPriority  = "set_priority"
+	BulkSetAssignees = "set_assignees"
+	BulkAddLabel     = "add_label"
+	BulkDelete       = "delete"
+)
+
+// Outcomes of a bulk request for one task
+const (
+	BulkUpdated  = "updated"
+	BulkDeleted  = "deleted"
+	BulkNotFound = "not_found"
+	BulkFailed   = "failed"
+)
+
+// BulkOperation is one change of a bulk request; only the field its Op needs is read
+type BulkOperation struct {
+	Op         string               `bson:"op" json:"op"`
+	Status     StatusType           `bson:"status,omitempty" json:"status,omitempty"`
+	Priority   PriorityType         `bson:"priority,omitempty" json:"priority,omitempty"`
+	AssignedTo []primitive.ObjectID `bson:"assigned_to,omitempty" json:"assigned_to,omitempty"`
+	Label      string               `bson:"label,omitempty" json:"label,omitempty"`
+}
+
+// BulkRequest applies every operation to the tasks listed in TaskIDs or matched
+// by Filter
+type BulkRequest struct {
+	TaskIDs    []primitive.ObjectID `json:"task_ids,omitempty"`
+	Filter     *TaskQuery           `json:"filter,omitempty"`
+	Operations []BulkOperation      `json:"operations"`
+}
+
+// BulkResult is what a bulk request did to one task
+type BulkResult struct {
+	TaskID primitive.ObjectID `json:"task_id"`
+	Status string             `json:"status"`
+	Error  string             `json:"error,omitempty"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// ExtractedItem is an action item the assistant found in a document. Owner is
+// the person as the document names them; DueDate is YYYY-MM-DD or empty.
+type ExtractedItem struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Owner       string `json:"owner"`
+	DueDate     string `json:"due_date"`
+	Priority    string `json:"priority"`
+	Quote       string `json:"quote"`
+}
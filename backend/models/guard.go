@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kinds of guard events
+const (
+	GuardInput      = "input"       // a chat message was refused
+	GuardToolArgs   = "tool_args"   // a tool call had missing or invalid arguments
+	GuardToolAccess = "tool_access" // a tool call targeted a task the user can't see
+	GuardInjection  = "injection"   // task content that reads like instructions was withheld
+)
+
+// GuardEvent records something the AI guardrails blocked, for review
+type GuardEvent struct {
+	ID        primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Kind      string                 `bson:"kind" json:"kind"`
+	UserID    *primitive.ObjectID    `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	TaskID    *primitive.ObjectID    `bson:"task_id,omitempty" json:"task_id,omitempty"`
+	Tool      string                 `bson:"tool,omitempty" json:"tool,omitempty"`
+	Args      map[string]interface{} `bson:"args,omitempty" json:"args,omitempty"`
+	Reason    string                 `bson:"reason" json:"reason"`
+	Excerpt   string                 `bson:"excerpt,omitempty" json:"excerpt,omitempty"`
+	CreatedAt time.Time              `bson:"created_at" json:"created_at"`
+}
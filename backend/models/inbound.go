@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InboundTemplate maps a generic JSON payload onto task fields. Title, Description and
+// Priority are Go text/template strings evaluated against the decoded payload,
+// e.g. "[{{.service}}] {{.alert.name}}". PriorityMap translates the rendered
+// priority (such as "critical") into one of low, medium or high.
+type InboundTemplate struct {
+	Title       string            `bson:"title" json:"title"`
+	Description string            `bson:"description" json:"description"`
+	Priority    string            `bson:"priority" json:"priority"`
+	PriorityMap map[string]string `bson:"priority_map,omitempty" json:"priority_map,omitempty"`
+}
+
+// InboundToken lets an external system create tasks in a workspace by posting to
+// /api/v1/inbound/:token.
+type InboundToken struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	WorkspaceID primitive.ObjectID   `bson:"workspace_id" json:"workspace_id"`
+	Name        string               `bson:"name" json:"name"`
+	Token       string               `bson:"token" json:"token"`
+	Template    InboundTemplate      `bson:"template" json:"template"`
+	AssignTo    []primitive.ObjectID `bson:"assign_to" json:"assign_to"`
+	UseAI       bool                 `bson:"use_ai" json:"use_ai"`
+	CreatedBy   primitive.ObjectID   `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time            `bson:"created_at" json:"created_at"`
+}
+
+type CreateInboundTokenRequest struct {
+	Name     string               `json:"name"`
+	Template InboundTemplate      `json:"template"`
+	AssignTo []primitive.ObjectID `json:"assign_to"`
+	UseAI    bool                 `json:"use_ai"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationMode controls how a user receives email notifications.
+type NotificationMode string
+
+const (
+	NotifyImmediate NotificationMode = "immediate"
+	NotifyDigest    NotificationMode = "digest"
+	NotifyOff       NotificationMode = "off"
+)
+
+func (m NotificationMode) ValidateNotificationMode() bool {
+	switch m {
+	case NotifyImmediate, NotifyDigest, NotifyOff:
+		return true
+	default:
+		return false
+	}
+}
+
+// NotificationEvent is the kind of task event a user is notified about.
+type NotificationEvent string
+
+const (
+	EventAssigned      NotificationEvent = "assigned"
+	EventMentioned     NotificationEvent = "mentioned"
+	EventDueSoon       NotificationEvent = "due_soon"
+	EventStatusChanged NotificationEvent = "status_changed"
+	EventStandup       NotificationEvent = "standup"
+)
+
+type EmailJobStatus string
+
+const (
+	EmailPending EmailJobStatus = "pending"
+	EmailSending EmailJobStatus = "sending"
+	EmailSent    EmailJobStatus = "sent"
+	EmailFailed  EmailJobStatus = "failed"
+)
+
+// EmailJob is a queued outgoing email, retried until it is sent or gives up.
+type EmailJob struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	To            string             `bson:"to" json:"to"`
+	Subject       string             `bson:"subject" json:"subject"`
+	TextBody      string             `bson:"text_body" json:"text_body"`
+	HTMLBody      string             `bson:"html_body" json:"html_body"`
+	Status        EmailJobStatus     `bson:"status" json:"status"`
+	Attempts      int                `bson:"attempts" json:"attempts"`
+	LastError     string             `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	NextAttemptAt time.Time          `bson:"next_attempt_at" json:"next_attempt_at"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	SentAt        *time.Time         `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+}
+
+// DigestItem is a notification held back for a user's daily digest email.
+type DigestItem struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Event     NotificationEvent  `bson:"event" json:"event"`
+	Summary   string             `bson:"summary" json:"summary"`
+	TaskID    primitive.ObjectID `bson:"task_id" json:"task_id"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// NotificationPreferencesRequest changes whichever preferences are present
+type NotificationPreferencesRequest struct {
+	NotificationMode NotificationMode `json:"notification_mode"`
+	DailySummary     *bool            `json:"daily_summary,omitempty"`
+}
+
+// Notification is an entry in a user's in-app inbox.
+type Notification struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	Event     NotificationEvent   `bson:"event" json:"event"`
+	Message   string              `bson:"message" json:"message"`
+	TaskID    *primitive.ObjectID `bson:"task_id,omitempty" json:"task_id,omitempty"`
+	ActorID   *primitive.ObjectID `bson:"actor_id,omitempty" json:"actor_id,omitempty"`
+	Read      bool                `bson:"read" json:"read"`
+	ReadAt    *time.Time          `bson:"read_at,omitempty" json:"read_at,omitempty"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+}
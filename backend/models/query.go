@@ -0,0 +1,20 @@
+package models
+
+// TaskQuery is a structured task filter, usually translated from a question by
+// the assistant. Dates are YYYY-MM-DD; Assignee is "me", a name or an email and
+// Workspace a workspace name.
+type TaskQuery struct {
+	Status        []StatusType   `json:"status,omitempty"`
+	Priority      []PriorityType `json:"priority,omitempty"`
+	Assignee      string         `json:"assignee,omitempty"`
+	Workspace     string         `json:"workspace,omitempty"`
+	Labels        []string       `json:"labels,omitempty"`
+	Text          string         `json:"text,omitempty"`
+	Overdue       bool           `json:"overdue,omitempty"`
+	DueAfter      string         `json:"due_after,omitempty"`
+	DueBefore     string         `json:"due_before,omitempty"`
+	CreatedAfter  string         `json:"created_after,omitempty"`
+	CreatedBefore string         `json:"created_before,omitempty"`
+	Sort          string         `json:"sort,omitempty"`
+	Limit         int            `json:"limit,omitempty"`
+}
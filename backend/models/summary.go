@@ -0,0 +1,19 @@
+package models
+
+// Scopes and periods of an AI summary
+const (
+	SummaryScopeMe   = "me"
+	SummaryScopeTeam = "team"
+
+	SummaryPeriodDay  = "day"
+	SummaryPeriodWeek = "week"
+)
+
+// Standup is the structured summary the assistant writes from recent task activity
+type Standup struct {
+	Summary  string   `json:"summary"`
+	Done     []string `json:"done"`
+	Doing    []string `json:"doing"`
+	Blockers []string `json:"blockers"`
+	Risks    []string `json:"risks"`
+}
@@ -13,15 +13,46 @@ type Task struct {
 	Description string               `bson:"description" json:"description"`
 	AssignedTo  []primitive.ObjectID `bson:"assigned_to" json:"assigned_to"`
 	AssignedBy  primitive.ObjectID   `bson:"assigned_by" json:"assigned_by"`
+	WorkspaceID *primitive.ObjectID  `bson:"workspace_id,omitempty" json:"workspace_id,omitempty"`
 	Status      StatusType           `bson:"status" json:"status"`
 	Priority    PriorityType         `bson:"priority" json:"priority"`
-	CreatedAt   time.Time            `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time            `bson:"updated_at" json:"updated_at"`
+	DueDate     *time.Time           `bson:"due_date,omitempty" json:"due_date,omitempty"`
+	Labels      []string             `bson:"labels,omitempty" json:"labels,omitempty"`
+	// ParentID links a subtask to its parent; DependsOn lists tasks that must be done first
+	ParentID  *primitive.ObjectID  `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	DependsOn []primitive.ObjectID `bson:"depends_on,omitempty" json:"depends_on,omitempty"`
+	// EstimateMinutes and StoryPoints are the planned effort, TimeSpentSeconds the
+	// total of the task's time entries
+	EstimateMinutes  *int     `bson:"estimate_minutes,omitempty" json:"estimate_minutes,omitempty"`
+	StoryPoints      *float64 `bson:"story_points,omitempty" json:"story_points,omitempty"`
+	TimeSpentSeconds int64    `bson:"time_spent_seconds,omitempty" json:"time_spent_seconds"`
+	// ExternalSource and ExternalID identify a task imported from another tool
+	// (e.g. "github" and the issue URL) so re-importing updates it instead of duplicating it
+	ExternalSource string    `bson:"external_source,omitempty" json:"external_source,omitempty"`
+	ExternalID     string    `bson:"external_id,omitempty" json:"external_id,omitempty"`
+	CreatedAt      time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `bson:"updated_at" json:"updated_at"`
+	// StatusHistory records every status the task has been in, oldest first
+	StatusHistory []StatusChange `bson:"status_history,omitempty" json:"status_history,omitempty"`
+	// DueSoonNotified is set once the due-soon reminder has been sent
+	DueSoonNotified bool `bson:"due_soon_notified,omitempty" json:"-"`
+	// Version goes up with every update; updates must send it back in If-Match
+	Version int64 `bson:"version" json:"version"`
+	// DeletedAt and DeletedBy are set while the task is in the trash
+	DeletedAt *time.Time          `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy *primitive.ObjectID `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
 	// TODO check if mongodb automatically handle created at and updated at
 }
 
 type StatusType string
 
+// StatusChange is one entry of a task's status history
+type StatusChange struct {
+	Status StatusType         `bson:"status" json:"status"`
+	At     time.Time          `bson:"at" json:"at"`
+	By     primitive.ObjectID `bson:"by" json:"by"`
+}
+
 type PriorityType string
 
 const (
@@ -55,9 +86,12 @@ func (p PriorityType) ValidatePriority() bool {
 }
 
 type UpdateTaskRequest struct {
-	Title       *string               `json:"title,omitempty"`
-	Description *string               `json:"description,omitempty"`
-	Status      *StatusType           `json:"status,omitempty"`
-	Priority    *PriorityType         `json:"priority,omitempty"`
-	AssignedTo  *[]primitive.ObjectID `json:"assigned_to,omitempty"`
+	Title           *string               `json:"title,omitempty"`
+	Description     *string               `json:"description,omitempty"`
+	Status          *StatusType           `json:"status,omitempty"`
+	Priority        *PriorityType         `json:"priority,omitempty"`
+	AssignedTo      *[]primitive.ObjectID `json:"assigned_to,omitempty"`
+	DueDate         *time.Time            `json:"due_date,omitempty"`
+	EstimateMinutes *int                  `json:"estimate_minutes,omitempty"`
+	StoryPoints     *float64              `json:"story_points,omitempty"`
 }
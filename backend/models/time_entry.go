@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TimeEntry is time a user spent on a task, either from a timer or entered by hand.
+// EndedAt is nil while the timer is running.
+type TimeEntry struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TaskID          primitive.ObjectID `bson:"task_id" json:"task_id"`
+	UserID          primitive.ObjectID `bson:"user_id" json:"user_id"`
+	StartedAt       time.Time          `bson:"started_at" json:"started_at"`
+	EndedAt         *time.Time         `bson:"ended_at" json:"ended_at"`
+	DurationSeconds int64              `bson:"duration_seconds" json:"duration_seconds"`
+	Note            string             `bson:"note,omitempty" json:"note,omitempty"`
+	Manual          bool               `bson:"manual" json:"manual"`
+	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// TimeEntryRequest adds time by hand: either an end time or a number of minutes
+type TimeEntryRequest struct {
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Minutes   int        `json:"minutes,omitempty"`
+	Note      string     `json:"note,omitempty"`
+}
+
+// TimeTotal is the time spent grouped by task, user or day
+type TimeTotal struct {
+	Key             string `bson:"_id" json:"key"`
+	Label           string `bson:"-" json:"label,omitempty"`
+	DurationSeconds int64  `bson:"duration_seconds" json:"duration_seconds"`
+	Entries         int    `bson:"entries" json:"entries"`
+}
@@ -6,6 +6,10 @@ type User struct {
     Name     string             `bson:"name" json:"name"`
     Email    string             `bson:"email" json:"email"`
     Password string             `bson:"password" json:"password"` 
+    NotificationMode NotificationMode `bson:"notification_mode,omitempty" json:"notification_mode,omitempty"`
+    CalendarToken string `bson:"calendar_token,omitempty" json:"-"`
+    // DailySummary posts an AI standup to the user's inbox every morning
+    DailySummary bool `bson:"daily_summary,omitempty" json:"daily_summary,omitempty"`
 }
 type UserRequest struct {
 	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Task events that can be delivered to webhooks. They match the "event" field
+// of the messages broadcast over the WebSocket.
+const (
+	WebhookTaskCreated  = "task_created"
+	WebhookTaskUpdated  = "task_updated"
+	WebhookTaskDeleted  = "task_deleted"
+	WebhookTaskRestored = "task_restored"
+)
+
+func ValidateWebhookEvent(event string) bool {
+	switch event {
+	case WebhookTaskCreated, WebhookTaskUpdated, WebhookTaskDeleted, WebhookTaskRestored:
+		return true
+	default:
+		return false
+	}
+}
+
+// WebhookSubscription sends a workspace's task events to an external URL.
+type WebhookSubscription struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WorkspaceID primitive.ObjectID `bson:"workspace_id" json:"workspace_id"`
+	URL         string             `bson:"url" json:"url"`
+	Events      []string           `bson:"events" json:"events"`
+	Secret      string             `bson:"secret" json:"secret,omitempty"`
+	Active      bool               `bson:"active" json:"active"`
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+func (s WebhookSubscription) Wants(event string) bool {
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// DeliveryAttempt records a single POST of a webhook delivery.
+type DeliveryAttempt struct {
+	At         time.Time `bson:"at" json:"at"`
+	StatusCode int       `bson:"status_code,omitempty" json:"status_code,omitempty"`
+	Error      string    `bson:"error,omitempty" json:"error,omitempty"`
+	DurationMs int64     `bson:"duration_ms" json:"duration_ms"`
+}
+
+// WebhookDelivery is one event queued for one subscription.
+type WebhookDelivery struct {
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	SubscriptionID primitive.ObjectID  `bson:"subscription_id" json:"subscription_id"`
+	WorkspaceID    primitive.ObjectID  `bson:"workspace_id" json:"workspace_id"`
+	Event          string              `bson:"event" json:"event"`
+	Payload        string              `bson:"payload" json:"payload"`
+	Status         DeliveryStatus      `bson:"status" json:"status"`
+	Attempts       []DeliveryAttempt   `bson:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time           `bson:"next_attempt_at" json:"next_attempt_at"`
+	RedeliveryOf   *primitive.ObjectID `bson:"redelivery_of,omitempty" json:"redelivery_of,omitempty"`
+	CreatedAt      time.Time           `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Workspace groups users and the tasks they share.
+type Workspace struct {
+	ID        primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name      string               `bson:"name" json:"name"`
+	OwnerID   primitive.ObjectID   `bson:"owner_id" json:"owner_id"`
+	Members   []primitive.ObjectID `bson:"members" json:"members"`
+	CreatedAt time.Time            `bson:"created_at" json:"created_at"`
+}
+
+func (w Workspace) IsMember(userID primitive.ObjectID) bool {
+	for _, member := range w.Members {
+		if member == userID {
+			return true
+		}
+	}
+	return false
+}
+
+type AddMemberRequest struct {
+	UserID primitive.ObjectID `json:"user_id"`
+}
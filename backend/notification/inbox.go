@@ -0,0 +1,28 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/ws"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AddToInbox stores a notification in the user's inbox and pushes it over the
+// WebSocket if they are online. Offline users see it next time they fetch their inbox.
+func AddToInbox(ctx context.Context, notification models.Notification) (*models.Notification, error) {
+	notification.ID = primitive.NewObjectID()
+	notification.Read = false
+	notification.CreatedAt = time.Now()
+	if _, err := database.GetCollection("notification").InsertOne(ctx, notification); err != nil {
+		return nil, fmt.Errorf("failed to store notification: %v", err)
+	}
+	ws.WSManager.SendToUser(notification.UserID, ws.WebSocketMessage{
+		Type:    ws.MessageTypeNotification,
+		Payload: notification,
+	})
+	return &notification, nil
+}
@@ -0,0 +1,145 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Message is a rendered email with both a plain text and an HTML body.
+type Message struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Mailer delivers a single email. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// SMTPMailer sends mail through an SMTP relay (MailHog works for local testing)
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// NewMailerFromEnv builds an SMTPMailer from the SMTP_* variables, falling back to
+// a LogMailer when SMTP_HOST is not set so development setups still work.
+func NewMailerFromEnv() Mailer {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		log.Println("SMTP_HOST not set, emails will only be logged")
+		return LogMailer{}
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "25"
+	}
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "no-reply@ai-task-manager.local"
+	}
+	return &SMTPMailer{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	body, err := buildMIME(m.From, msg)
+	if err != nil {
+		return err
+	}
+	// The envelope sender must be a bare address even if From has a display name
+	sender, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP_FROM address: %v", err)
+	}
+	if err := smtp.SendMail(m.Host+":"+m.Port, auth, sender.Address, []string{msg.To}, body); err != nil {
+		return fmt.Errorf("smtp send to %s: %v", msg.To, err)
+	}
+	return nil
+}
+
+// LogMailer writes emails to the log instead of sending them
+type LogMailer struct{}
+
+func (LogMailer) Send(msg Message) error {
+	log.Printf("email to %s: %s\n%s", msg.To, msg.Subject, msg.TextBody)
+	return nil
+}
+
+// buildMIME renders msg as a multipart/alternative message
+func buildMIME(from string, msg Message) ([]byte, error) {
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	headers := []string{
+		"From: " + from,
+		"To: " + msg.To,
+		"Subject: " + encodeHeader(msg.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + boundary,
+	}
+	buf.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=UTF-8", msg.TextBody},
+		{"text/html; charset=UTF-8", msg.HTMLBody},
+	}
+	for _, part := range parts {
+		if part.body == "" {
+			continue
+		}
+		buf.WriteString("--" + boundary + "\r\n")
+		buf.WriteString("Content-Type: " + part.contentType + "\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		qp.Close()
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+	return buf.Bytes(), nil
+}
+
+func encodeHeader(s string) string {
+	return mime.BEncoding.Encode("UTF-8", s)
+}
+
+func randomBoundary() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate boundary: %v", err)
+	}
+	return "task-manager-" + hex.EncodeToString(b), nil
+}
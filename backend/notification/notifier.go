@@ -0,0 +1,309 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event describes something that happened to a task that users may be told about
+type Event struct {
+	Type       models.NotificationEvent
+	Task       models.Task
+	ActorID    primitive.ObjectID
+	Recipients []primitive.ObjectID
+	OldStatus  models.StatusType
+}
+
+var mentionPattern = regexp.MustCompile(`(?:^|\s)@([A-Za-z0-9._-]+)`)
+
+// Start launches the email queue worker, the daily digest job and the due-soon scanner.
+func Start(ctx context.Context) {
+	mailer := NewMailerFromEnv()
+	go runQueueWorker(ctx, mailer, 10*time.Second)
+	go runDigestJob(ctx, digestHour())
+	go runDueSoonScanner(ctx, 15*time.Minute, 24*time.Hour)
+}
+
+// Notify adds event to each recipient's inbox and emails it according to their
+// notification preference. The actor is never notified about their own change.
+func Notify(ctx context.Context, event Event) {
+	recipients := make([]primitive.ObjectID, 0, len(event.Recipients))
+	seen := map[primitive.ObjectID]bool{}
+	for _, id := range event.Recipients {
+		if id.IsZero() || id == event.ActorID || seen[id] {
+			continue
+		}
+		seen[id] = true
+		recipients = append(recipients, id)
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	users, err := findUsers(ctx, append(recipients, event.ActorID))
+	if err != nil {
+		log.Printf("Notification: failed to load users: %v", err)
+		return
+	}
+	actorName := "Someone"
+	if actor, ok := users[event.ActorID]; ok {
+		actorName = actor.Name
+	}
+
+	for _, id := range recipients {
+		user, ok := users[id]
+		if !ok {
+			continue
+		}
+		data := templateData{
+			RecipientName: user.Name,
+			ActorName:     actorName,
+			Task:          event.Task,
+			OldStatus:     event.OldStatus,
+			TaskURL:       taskURL(event.Task.ID),
+		}
+		inboxItem := models.Notification{
+			UserID:  user.ID,
+			Event:   event.Type,
+			Message: summaryLine(event.Type, data),
+		}
+		if !event.Task.ID.IsZero() {
+			inboxItem.TaskID = &event.Task.ID
+		}
+		if !event.ActorID.IsZero() {
+			inboxItem.ActorID = &event.ActorID
+		}
+		if _, err := AddToInbox(ctx, inboxItem); err != nil {
+			log.Printf("Notification: %v", err)
+		}
+		if err := deliver(ctx, user, event.Type, data); err != nil {
+			log.Printf("Notification: failed to deliver %s to %s: %v", event.Type, user.Email, err)
+		}
+	}
+}
+
+// deliver routes a rendered event to the user's email according to their preference
+func deliver(ctx context.Context, user models.User, eventType models.NotificationEvent, data templateData) error {
+	switch user.NotificationMode {
+	case models.NotifyOff:
+		return nil
+	case models.NotifyDigest:
+		item := models.DigestItem{
+			ID:        primitive.NewObjectID(),
+			UserID:    user.ID,
+			Event:     eventType,
+			Summary:   summaryLine(eventType, data),
+			TaskID:    data.Task.ID,
+			CreatedAt: time.Now(),
+		}
+		_, err := database.GetCollection("notification_digest").InsertOne(ctx, item)
+		return err
+	default:
+		tmpl, ok := templates[eventType]
+		if !ok {
+			return fmt.Errorf("no template for event %s", eventType)
+		}
+		msg, err := tmpl.render(user.Email, data.Task.Title, data)
+		if err != nil {
+			return err
+		}
+		return Enqueue(ctx, msg)
+	}
+}
+
+// MentionedUsers resolves @handles in text to users, matching either the local part
+// of their email or their name with spaces removed (case-insensitive).
+func MentionedUsers(ctx context.Context, text string) ([]primitive.ObjectID, error) {
+	matches := mentionPattern.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	handles := map[string]bool{}
+	for _, m := range matches {
+		handles[strings.ToLower(strings.TrimRight(m[1], "."))] = true
+	}
+
+	cursor, err := database.GetCollection("user").Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch users: %v", err)
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, fmt.Errorf("failed to parse users: %v", err)
+	}
+
+	var ids []primitive.ObjectID
+	for _, user := range users {
+		localPart := strings.ToLower(strings.SplitN(user.Email, "@", 2)[0])
+		compactName := strings.ToLower(strings.ReplaceAll(user.Name, " ", ""))
+		if handles[localPart] || handles[compactName] {
+			ids = append(ids, user.ID)
+		}
+	}
+	return ids, nil
+}
+
+func findUsers(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.User, error) {
+	cursor, err := database.GetCollection("user").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	return byID, nil
+}
+
+func taskURL(id primitive.ObjectID) string {
+	return strings.TrimRight(os.Getenv("ORIGIN_URL"), "/") + "/dashboard/tasks/" + id.Hex()
+}
+
+// digestHour reads DIGEST_HOUR (0-23, server local time), defaulting to 8am
+func digestHour() int {
+	hour, err := strconv.Atoi(os.Getenv("DIGEST_HOUR"))
+	if err != nil || hour < 0 || hour > 23 {
+		return 8
+	}
+	return hour
+}
+
+// runDigestJob sends the daily digest every day at the given hour
+func runDigestJob(ctx context.Context, hour int) {
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			if err := SendDigests(ctx); err != nil {
+				log.Printf("Notification: digest run failed: %v", err)
+			}
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// SendDigests groups pending digest items per user and queues one email each
+func SendDigests(ctx context.Context) error {
+	digests := database.GetCollection("notification_digest")
+	cursor, err := digests.Find(ctx, bson.M{})
+	if err != nil {
+		return fmt.Errorf("could not fetch digest items: %v", err)
+	}
+	var items []models.DigestItem
+	if err := cursor.All(ctx, &items); err != nil {
+		return fmt.Errorf("failed to parse digest items: %v", err)
+	}
+
+	byUser := map[primitive.ObjectID][]models.DigestItem{}
+	var userIDs []primitive.ObjectID
+	for _, item := range items {
+		if _, ok := byUser[item.UserID]; !ok {
+			userIDs = append(userIDs, item.UserID)
+		}
+		byUser[item.UserID] = append(byUser[item.UserID], item)
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+	users, err := findUsers(ctx, userIDs)
+	if err != nil {
+		return fmt.Errorf("could not fetch users: %v", err)
+	}
+
+	for userID, userItems := range byUser {
+		var itemIDs []primitive.ObjectID
+		for _, item := range userItems {
+			itemIDs = append(itemIDs, item.ID)
+		}
+		user, ok := users[userID]
+		// Users who switched to immediate/off since the items were stored get no digest
+		if ok && user.NotificationMode == models.NotifyDigest {
+			data := templateData{RecipientName: user.Name, Items: userItems}
+			msg, err := digestTemplate.render(user.Email, strconv.Itoa(len(userItems)), data)
+			if err != nil {
+				log.Printf("Notification: failed to render digest for %s: %v", user.Email, err)
+				continue
+			}
+			if err := Enqueue(ctx, msg); err != nil {
+				log.Printf("Notification: failed to queue digest for %s: %v", user.Email, err)
+				continue
+			}
+		}
+		if _, err := digests.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": itemIDs}}); err != nil {
+			log.Printf("Notification: failed to clear digest items for %s: %v", userID.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// runDueSoonScanner periodically notifies assignees of unfinished tasks due within window
+func runDueSoonScanner(ctx context.Context, interval time.Duration, window time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := notifyDueSoon(ctx, window); err != nil {
+			log.Printf("Notification: due-soon scan failed: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func notifyDueSoon(ctx context.Context, window time.Duration) error {
+	taskCollection := database.GetCollection("task")
+	now := time.Now()
+	filter := bson.M{
+		"due_date":          bson.M{"$gte": now, "$lte": now.Add(window)},
+		"status":            bson.M{"$ne": models.COMPLETED},
+		"due_soon_notified": bson.M{"$ne": true},
+	}
+	cursor, err := taskCollection.Find(ctx, trash.Live(filter))
+	if err != nil {
+		return fmt.Errorf("could not fetch due tasks: %v", err)
+	}
+	var tasks []models.Task
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return fmt.Errorf("failed to parse due tasks: %v", err)
+	}
+
+	for _, task := range tasks {
+		// Mark first so a slow send can't cause the next scan to notify twice
+		if _, err := taskCollection.UpdateByID(ctx, task.ID, bson.M{"$set": bson.M{"due_soon_notified": true}}); err != nil {
+			log.Printf("Notification: failed to flag task %s: %v", task.ID.Hex(), err)
+			continue
+		}
+		Notify(ctx, Event{
+			Type:       models.EventDueSoon,
+			Task:       task,
+			Recipients: task.AssignedTo,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	maxEmailAttempts = 5
+	baseRetryDelay   = 30 * time.Second
+	// jobs stuck in "sending" longer than this are assumed lost (e.g. crash mid-send)
+	sendingTimeout = 5 * time.Minute
+)
+
+// Enqueue stores msg in the email queue; the queue worker delivers it
+func Enqueue(ctx context.Context, msg Message) error {
+	now := time.Now()
+	job := models.EmailJob{
+		ID:            primitive.NewObjectID(),
+		To:            msg.To,
+		Subject:       msg.Subject,
+		TextBody:      msg.TextBody,
+		HTMLBody:      msg.HTMLBody,
+		Status:        models.EmailPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	if _, err := database.GetCollection("email_queue").InsertOne(ctx, job); err != nil {
+		return fmt.Errorf("failed to enqueue email: %v", err)
+	}
+	return nil
+}
+
+// retryDelay is the exponential backoff applied after the given number of failed attempts
+func retryDelay(attempts int) time.Duration {
+	return baseRetryDelay * time.Duration(math.Pow(2, float64(attempts-1)))
+}
+
+// processQueue claims due jobs one at a time and sends them until none are left
+func processQueue(ctx context.Context, mailer Mailer) {
+	queue := database.GetCollection("email_queue")
+	for {
+		now := time.Now()
+		filter := bson.M{
+			"$or": []bson.M{
+				{"status": models.EmailPending, "next_attempt_at": bson.M{"$lte": now}},
+				{"status": models.EmailSending, "next_attempt_at": bson.M{"$lte": now.Add(-sendingTimeout)}},
+			},
+		}
+		// Claiming with FindOneAndUpdate keeps two workers from sending the same job
+		claim := bson.M{"$set": bson.M{"status": models.EmailSending, "next_attempt_at": now}}
+		opts := options.FindOneAndUpdate().
+			SetSort(bson.M{"next_attempt_at": 1}).
+			SetReturnDocument(options.After)
+
+		var job models.EmailJob
+		err := queue.FindOneAndUpdate(ctx, filter, claim, opts).Decode(&job)
+		if err == mongo.ErrNoDocuments {
+			return
+		}
+		if err != nil {
+			log.Printf("Email queue: failed to claim job: %v", err)
+			return
+		}
+
+		sendErr := mailer.Send(Message{
+			To:       job.To,
+			Subject:  job.Subject,
+			TextBody: job.TextBody,
+			HTMLBody: job.HTMLBody,
+		})
+
+		update := bson.M{}
+		if sendErr == nil {
+			sentAt := time.Now()
+			update["$set"] = bson.M{"status": models.EmailSent, "sent_at": sentAt}
+		} else {
+			attempts := job.Attempts + 1
+			set := bson.M{"attempts": attempts, "last_error": sendErr.Error()}
+			if attempts >= maxEmailAttempts {
+				set["status"] = models.EmailFailed
+				log.Printf("Email queue: giving up on %s after %d attempts: %v", job.To, attempts, sendErr)
+			} else {
+				set["status"] = models.EmailPending
+				set["next_attempt_at"] = time.Now().Add(retryDelay(attempts))
+				log.Printf("Email queue: attempt %d to %s failed: %v", attempts, job.To, sendErr)
+			}
+			update["$set"] = set
+		}
+		if _, err := queue.UpdateByID(ctx, job.ID, update); err != nil {
+			log.Printf("Email queue: failed to update job %s: %v", job.ID.Hex(), err)
+		}
+	}
+}
+
+// runQueueWorker polls the queue until ctx is cancelled
+func runQueueWorker(ctx context.Context, mailer Mailer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		processQueue(ctx, mailer)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/Atif-27/ai-task-manager/models"
+)
+
+// templateData is what every email template is rendered with
+type templateData struct {
+	RecipientName string
+	ActorName     string
+	Task          models.Task
+	OldStatus     models.StatusType
+	TaskURL       string
+	Items         []models.DigestItem
+}
+
+type emailTemplate struct {
+	subject string
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+const htmlLayoutStart = `<!DOCTYPE html><html><body style="font-family:Arial,sans-serif;color:#222">`
+const htmlLayoutEnd = `<p style="color:#888;font-size:12px">You can change how you receive these emails in your notification settings.</p></body></html>`
+
+var templates = map[models.NotificationEvent]emailTemplate{
+	models.EventAssigned: newEmailTemplate(
+		"You were assigned: %s",
+		`Hi {{.RecipientName}},
+
+{{.ActorName}} assigned you to "{{.Task.Title}}" (priority: {{.Task.Priority}}).
+{{if .Task.Description}}
+{{.Task.Description}}
+{{end}}
+View the task: {{.TaskURL}}
+`,
+		`<p>Hi {{.RecipientName}},</p>
+<p><b>{{.ActorName}}</b> assigned you to <b>{{.Task.Title}}</b> (priority: {{.Task.Priority}}).</p>
+{{if .Task.Description}}<p>{{.Task.Description}}</p>{{end}}
+<p><a href="{{.TaskURL}}">View the task</a></p>`,
+	),
+	models.EventMentioned: newEmailTemplate(
+		"You were mentioned in: %s",
+		`Hi {{.RecipientName}},
+
+{{.ActorName}} mentioned you in "{{.Task.Title}}".
+
+{{.Task.Description}}
+
+View the task: {{.TaskURL}}
+`,
+		`<p>Hi {{.RecipientName}},</p>
+<p><b>{{.ActorName}}</b> mentioned you in <b>{{.Task.Title}}</b>.</p>
+<blockquote>{{.Task.Description}}</blockquote>
+<p><a href="{{.TaskURL}}">View the task</a></p>`,
+	),
+	models.EventDueSoon: newEmailTemplate(
+		"Due soon: %s",
+		`Hi {{.RecipientName}},
+
+"{{.Task.Title}}" is due {{if .Task.DueDate}}{{.Task.DueDate.Format "Mon Jan 2 15:04 MST"}}{{end}} and is still {{.Task.Status}}.
+
+View the task: {{.TaskURL}}
+`,
+		`<p>Hi {{.RecipientName}},</p>
+<p><b>{{.Task.Title}}</b> is due {{if .Task.DueDate}}{{.Task.DueDate.Format "Mon Jan 2 15:04 MST"}}{{end}} and is still <i>{{.Task.Status}}</i>.</p>
+<p><a href="{{.TaskURL}}">View the task</a></p>`,
+	),
+	models.EventStatusChanged: newEmailTemplate(
+		"Status changed: %s",
+		`Hi {{.RecipientName}},
+
+{{.ActorName}} moved "{{.Task.Title}}" from {{.OldStatus}} to {{.Task.Status}}.
+
+View the task: {{.TaskURL}}
+`,
+		`<p>Hi {{.RecipientName}},</p>
+<p><b>{{.ActorName}}</b> moved <b>{{.Task.Title}}</b> from <i>{{.OldStatus}}</i> to <i>{{.Task.Status}}</i>.</p>
+<p><a href="{{.TaskURL}}">View the task</a></p>`,
+	),
+}
+
+var digestTemplate = newEmailTemplate(
+	"Your daily task digest (%s updates)",
+	`Hi {{.RecipientName}},
+
+Here is what happened since your last digest:
+{{range .Items}}
+- {{.Summary}}{{end}}
+`,
+	`<p>Hi {{.RecipientName}},</p>
+<p>Here is what happened since your last digest:</p>
+<ul>{{range .Items}}<li>{{.Summary}}</li>{{end}}</ul>`,
+)
+
+func newEmailTemplate(subject, text, html string) emailTemplate {
+	return emailTemplate{
+		subject: subject,
+		text:    texttemplate.Must(texttemplate.New("text").Parse(text)),
+		html:    htmltemplate.Must(htmltemplate.New("html").Parse(htmlLayoutStart + html + htmlLayoutEnd)),
+	}
+}
+
+// render executes both bodies of the template; subjectArg fills the %s in the subject
+func (t emailTemplate) render(to string, subjectArg string, data templateData) (Message, error) {
+	var text, html bytes.Buffer
+	if err := t.text.Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render text template: %v", err)
+	}
+	if err := t.html.Execute(&html, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render html template: %v", err)
+	}
+	return Message{
+		To:       to,
+		Subject:  fmt.Sprintf(t.subject, subjectArg),
+		TextBody: strings.TrimSpace(text.String()) + "\n",
+		HTMLBody: html.String(),
+	}, nil
+}
+
+// summaryLine is the one-line description of an event used in digests
+func summaryLine(event models.NotificationEvent, data templateData) string {
+	switch event {
+	case models.EventAssigned:
+		return fmt.Sprintf("%s assigned you to \"%s\"", data.ActorName, data.Task.Title)
+	case models.EventMentioned:
+		return fmt.Sprintf("%s mentioned you in \"%s\"", data.ActorName, data.Task.Title)
+	case models.EventDueSoon:
+		return fmt.Sprintf("\"%s\" is due soon", data.Task.Title)
+	case models.EventStatusChanged:
+		return fmt.Sprintf("%s moved \"%s\" from %s to %s", data.ActorName, data.Task.Title, data.OldStatus, data.Task.Status)
+	default:
+		return data.Task.Title
+	}
+}
@@ -0,0 +1,329 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/guard"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// Sort orders a query can ask for
+const (
+	SortDueDate   = "due_date"
+	SortPriority  = "priority"
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+)
+
+// Error is a problem with the query itself, such as an unknown workspace, as
+// opposed to a failure of the task store
+type Error struct {
+	Message string
+}
+
+func (e Error) Error() string {
+	return e.Message
+}
+
+func invalid(format string, args ...interface{}) error {
+	return Error{Message: fmt.Sprintf(format, args...)}
+}
+
+var priorityRank = map[models.PriorityType]int{models.HIGH: 0, models.MEDIUM: 1, models.LOW: 2}
+
+// Validate normalises the query and rejects values the store can't match on
+func Validate(q *models.TaskQuery) error {
+	for i, status := range q.Status {
+		q.Status[i] = models.StatusType(strings.ToLower(strings.TrimSpace(string(status))))
+		if !q.Status[i].ValidateStatus() {
+			return invalid("invalid status %q", status)
+		}
+	}
+	for i, priority := range q.Priority {
+		q.Priority[i] = models.PriorityType(strings.ToLower(strings.TrimSpace(string(priority))))
+		if !q.Priority[i].ValidatePriority() {
+			return invalid("invalid priority %q", priority)
+		}
+	}
+	q.Assignee = strings.TrimSpace(q.Assignee)
+	q.Workspace = strings.TrimSpace(q.Workspace)
+	q.Text = strings.TrimSpace(q.Text)
+	for name, value := range map[string]string{
+		"due_after": q.DueAfter, "due_before": q.DueBefore,
+		"created_after": q.CreatedAfter, "created_before": q.CreatedBefore,
+	} {
+		if _, err := parseDate(value); err != nil {
+			return invalid("%s must be YYYY-MM-DD", name)
+		}
+	}
+	switch q.Sort {
+	case "":
+		q.Sort = SortDueDate
+	case SortDueDate, SortPriority, SortCreatedAt, SortUpdatedAt:
+	default:
+		return invalid("sort must be due_date, priority, created_at or updated_at")
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	if q.Limit > MaxLimit {
+		q.Limit = MaxLimit
+	}
+	return nil
+}
+
+// parseDate returns nil for an empty date
+func parseDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// WorkspaceNames lists the names of the user's workspaces
+func WorkspaceNames(ctx context.Context, userID primitive.ObjectID) ([]string, error) {
+	workspaces, err := memberWorkspaces(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(workspaces))
+	for i, workspace := range workspaces {
+		names[i] = workspace.Name
+	}
+	return names, nil
+}
+
+func memberWorkspaces(ctx context.Context, userID primitive.ObjectID) ([]models.Workspace, error) {
+	cursor, err := database.GetCollection("workspace").Find(ctx, bson.M{"members": userID})
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch workspaces: %v", err)
+	}
+	var workspaces []models.Workspace
+	if err := cursor.All(ctx, &workspaces); err != nil {
+		return nil, fmt.Errorf("failed to parse workspaces: %v", err)
+	}
+	return workspaces, nil
+}
+
+// Run validates the query and returns the matching tasks the user can see: tasks
+// outside any workspace and tasks in the workspaces they belong to
+func Run(ctx context.Context, userID primitive.ObjectID, q *models.TaskQuery, now time.Time) ([]models.Task, error) {
+	if err := Validate(q); err != nil {
+		return nil, err
+	}
+	filter, err := build(ctx, userID, *q, now)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetLimit(int64(q.Limit))
+	switch q.Sort {
+	case SortDueDate:
+		opts.SetSort(bson.D{{Key: "due_date", Value: 1}, {Key: "_id", Value: 1}})
+	case SortCreatedAt:
+		opts.SetSort(bson.D{{Key: "created_at", Value: -1}})
+	case SortUpdatedAt:
+		opts.SetSort(bson.D{{Key: "updated_at", Value: -1}})
+	case SortPriority:
+		// Priorities don't sort alphabetically, so order them after the fetch
+		opts.SetLimit(0)
+	}
+	cursor, err := database.GetCollection("task").Find(ctx, trash.Live(filter), opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch tasks: %v", err)
+	}
+	tasks := []models.Task{}
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to parse tasks: %v", err)
+	}
+	if q.Sort == SortPriority {
+		sort.SliceStable(tasks, func(i, j int) bool { return priorityRank[tasks[i].Priority] < priorityRank[tasks[j].Priority] })
+		if len(tasks) > q.Limit {
+			tasks = tasks[:q.Limit]
+		}
+	}
+	return tasks, nil
+}
+
+// Count validates the query and returns how many tasks the user can see match
+// it, regardless of its limit
+func Count(ctx context.Context, userID primitive.ObjectID, q *models.TaskQuery, now time.Time) (int64, error) {
+	if err := Validate(q); err != nil {
+		return 0, err
+	}
+	filter, err := build(ctx, userID, *q, now)
+	if err != nil {
+		return 0, err
+	}
+	count, err := database.GetCollection("task").CountDocuments(ctx, trash.Live(filter))
+	if err != nil {
+		return 0, fmt.Errorf("could not count tasks: %v", err)
+	}
+	return count, nil
+}
+
+// build turns a validated query into a MongoDB filter, resolving workspace and
+// assignee names
+func build(ctx context.Context, userID primitive.ObjectID, q models.TaskQuery, now time.Time) (bson.M, error) {
+	workspaces, err := memberWorkspaces(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var and []bson.M
+	if q.Workspace != "" {
+		var match *models.Workspace
+		for i := range workspaces {
+			if strings.EqualFold(workspaces[i].Name, q.Workspace) {
+				match = &workspaces[i]
+				break
+			}
+		}
+		if match == nil {
+			return nil, invalid("you are not in a workspace named %q", q.Workspace)
+		}
+		and = append(and, bson.M{"workspace_id": match.ID})
+	} else {
+		and = append(and, visible(workspaces))
+	}
+
+	if q.Assignee != "" {
+		assignees, err := resolveAssignee(ctx, userID, q.Assignee)
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, bson.M{"assigned_to": bson.M{"$in": assignees}})
+	}
+	if len(q.Status) > 0 {
+		and = append(and, bson.M{"status": bson.M{"$in": q.Status}})
+	}
+	if len(q.Priority) > 0 {
+		and = append(and, bson.M{"priority": bson.M{"$in": q.Priority}})
+	}
+	if len(q.Labels) > 0 {
+		labels := make([]primitive.Regex, len(q.Labels))
+		for i, label := range q.Labels {
+			labels[i] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(strings.TrimSpace(label)) + "$", Options: "i"}
+		}
+		and = append(and, bson.M{"labels": bson.M{"$in": labels}})
+	}
+	if q.Text != "" {
+		text := primitive.Regex{Pattern: regexp.QuoteMeta(q.Text), Options: "i"}
+		and = append(and, bson.M{"$or": []bson.M{{"title": text}, {"description": text}}})
+	}
+	if q.Overdue {
+		and = append(and, bson.M{"status": bson.M{"$ne": models.COMPLETED}, "due_date": bson.M{"$lt": now}})
+	}
+
+	// Parse errors were caught by Validate; before dates include the whole day
+	dueAfter, _ := parseDate(q.DueAfter)
+	dueBefore, _ := parseDate(q.DueBefore)
+	createdAfter, _ := parseDate(q.CreatedAfter)
+	createdBefore, _ := parseDate(q.CreatedBefore)
+	if r := dateRange(dueAfter, dueBefore); r != nil {
+		and = append(and, bson.M{"due_date": r})
+	}
+	if r := dateRange(createdAfter, createdBefore); r != nil {
+		and = append(and, bson.M{"created_at": r})
+	}
+	return bson.M{"$and": and}, nil
+}
+
+// visible matches tasks outside any workspace and tasks in workspaces
+func visible(workspaces []models.Workspace) bson.M {
+	ids := make([]primitive.ObjectID, len(workspaces))
+	for i, workspace := range workspaces {
+		ids[i] = workspace.ID
+	}
+	return bson.M{"$or": []bson.M{{"workspace_id": nil}, {"workspace_id": bson.M{"$in": ids}}}}
+}
+
+// Visible returns a filter for the tasks the user can see, the same ones Run
+// searches: tasks outside any workspace and tasks in their workspaces
+func Visible(ctx context.Context, userID primitive.ObjectID) (bson.M, error) {
+	workspaces, err := memberWorkspaces(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return visible(workspaces), nil
+}
+
+func dateRange(after, before *time.Time) bson.M {
+	if after == nil && before == nil {
+		return nil
+	}
+	r := bson.M{}
+	if after != nil {
+		r["$gte"] = *after
+	}
+	if before != nil {
+		r["$lt"] = before.AddDate(0, 0, 1)
+	}
+	return r
+}
+
+// resolveAssignee matches "me", a user's email or their name, ignoring case
+func resolveAssignee(ctx context.Context, userID primitive.ObjectID, assignee string) ([]primitive.ObjectID, error) {
+	if strings.EqualFold(assignee, "me") {
+		return []primitive.ObjectID{userID}, nil
+	}
+	exact := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(assignee) + "$", Options: "i"}
+	cursor, err := database.GetCollection("user").Find(ctx,
+		bson.M{"$or": []bson.M{{"email": exact}, {"name": exact}}},
+		options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch users: %v", err)
+	}
+	var users []models.UserRequest
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, fmt.Errorf("failed to parse users: %v", err)
+	}
+	if len(users) == 0 {
+		return nil, invalid("no user named %q", assignee)
+	}
+	ids := make([]primitive.ObjectID, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids, nil
+}
+
+// Describe lists tasks as plain text for the assistant to answer from. Titles
+// and labels are written by users, so they go through guard.Untrusted.
+func Describe(ctx context.Context, tasks []models.Task) string {
+	if len(tasks) == 0 {
+		return "No matching tasks."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d matching tasks:\n", len(tasks))
+	for _, task := range tasks {
+		fmt.Fprintf(&b, "- %q priority=%s status=%s", guard.Untrusted(ctx, &task.ID, task.Title), task.Priority, task.Status)
+		if task.DueDate != nil {
+			fmt.Fprintf(&b, " due=%s", task.DueDate.Format("2006-01-02"))
+		}
+		if len(task.Labels) > 0 {
+			fmt.Fprintf(&b, " labels=%s", guard.Untrusted(ctx, &task.ID, strings.Join(task.Labels, ",")))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,356 @@
+package quickadd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/models"
+)
+
+// DefaultDueHour is the time of day a due date without a time is set to
+const DefaultDueHour = 17
+
+// Result is what Parse read from a quick-add line. Ambiguities lists the parts
+// it could not read with confidence; when there are any, the caller may ask the
+// assistant instead.
+type Result struct {
+	Title       string              `json:"title"`
+	Priority    models.PriorityType `json:"priority,omitempty"`
+	Assignees   []string            `json:"assignees,omitempty"`
+	Labels      []string            `json:"labels,omitempty"`
+	DueDate     *time.Time          `json:"due_date,omitempty"`
+	Ambiguities []string            `json:"ambiguities,omitempty"`
+}
+
+func (r Result) Ambiguous() bool {
+	return len(r.Ambiguities) > 0
+}
+
+var (
+	priorityToken = regexp.MustCompile(`^(?:!(high|medium|med|low|urgent|h|m|l|1|2|3)|p([1-3]))$`)
+	labelToken    = regexp.MustCompile(`^#([\p{L}\p{N}_\-/]+)$`)
+	numberOnly    = regexp.MustCompile(`^[0-9]+$`)
+	isoDate       = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	slashDate     = regexp.MustCompile(`^\d{1,2}/\d{1,2}(?:/\d{2,4})?$`)
+	clock         = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	ordinal       = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+)
+
+var priorities = map[string]models.PriorityType{
+	"high": models.HIGH, "urgent": models.HIGH, "h": models.HIGH, "1": models.HIGH,
+	"medium": models.MEDIUM, "med": models.MEDIUM, "m": models.MEDIUM, "2": models.MEDIUM,
+	"low": models.LOW, "l": models.LOW, "3": models.LOW,
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"jan": time.January, "january": time.January, "feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March, "apr": time.April, "april": time.April,
+	"may": time.May, "jun": time.June, "june": time.June, "jul": time.July, "july": time.July,
+	"aug": time.August, "august": time.August, "sep": time.September, "sept": time.September,
+	"september": time.September, "oct": time.October, "october": time.October,
+	"nov": time.November, "november": time.November, "dec": time.December, "december": time.December,
+}
+
+var numbers = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+// prepositions are dropped from the title together with the date after them;
+// "at" is read as part of a time
+var prepositions = map[string]bool{"due": true, "by": true, "on": true, "before": true, "at": true}
+
+// Parse reads priority (!high, p1), assignees (@name), labels (#tag) and a due
+// date ("tomorrow", "next fri", "in 3 days", "may 3 at 5pm") out of one line of
+// text; the rest is the title. Dates are relative to now and in its location.
+func Parse(text string, now time.Time) Result {
+	words := strings.Fields(text)
+	used := make([]bool, len(words))
+	var result Result
+	var dates []string
+
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		lower := strings.ToLower(trimPunct(word))
+
+		if m := priorityToken.FindStringSubmatch(lower); m != nil {
+			priority := priorities[m[1]]
+			if m[2] != "" {
+				priority = priorities[m[2]]
+			}
+			if result.Priority != "" && result.Priority != priority {
+				result.Ambiguities = append(result.Ambiguities, fmt.Sprintf("both %s and %s priority given", result.Priority, priority))
+			}
+			if result.Priority == "" {
+				result.Priority = priority
+			}
+			used[i] = true
+			continue
+		}
+		if strings.HasPrefix(word, "@") && len(trimPunct(word)) > 1 {
+			result.Assignees = appendUnique(result.Assignees, trimPunct(word)[1:])
+			used[i] = true
+			continue
+		}
+		if m := labelToken.FindStringSubmatch(trimPunct(word)); m != nil && !numberOnly.MatchString(m[1]) {
+			result.Labels = appendUnique(result.Labels, strings.ToLower(m[1]))
+			used[i] = true
+			continue
+		}
+
+		start := i
+		if prepositions[lower] && lower != "at" {
+			start = i + 1
+		}
+		// "sat" and "sun" are only days after a preposition
+		ordinaryWord := start == i && (lower == "sat" || lower == "sun")
+		if due, n, ok := matchDateTime(words, start, now); ok && !ordinaryWord {
+			if result.DueDate == nil {
+				result.DueDate = &due
+			}
+			dates = append(dates, trimPunct(strings.Join(words[i:start+n], " ")))
+			for j := i; j < start+n; j++ {
+				used[j] = true
+			}
+			i = start + n - 1
+			continue
+		}
+
+		switch {
+		case slashDate.MatchString(lower):
+			result.Ambiguities = append(result.Ambiguities, fmt.Sprintf("%q could be day/month or month/day", word))
+		case lower == "next" && i+1 < len(words):
+			result.Ambiguities = append(result.Ambiguities, fmt.Sprintf("could not read date %q", "next "+words[i+1]))
+		case lower == "due" && i+1 < len(words):
+			result.Ambiguities = append(result.Ambiguities, fmt.Sprintf("could not read due date after %q", "due"))
+		}
+	}
+	if len(dates) > 1 {
+		result.Ambiguities = append(result.Ambiguities, fmt.Sprintf("several dates given: %s", strings.Join(dates, ", ")))
+	}
+
+	var title []string
+	for i, word := range words {
+		if !used[i] {
+			title = append(title, word)
+		}
+	}
+	result.Title = strings.Join(title, " ")
+	if result.Title == "" {
+		result.Ambiguities = append(result.Ambiguities, "no title left after parsing")
+	}
+	return result
+}
+
+// matchDateTime reads a date with an optional time after it, or a time on its
+// own (today, or tomorrow if it has passed). It returns the number of words used.
+func matchDateTime(words []string, i int, now time.Time) (time.Time, int, bool) {
+	if i >= len(words) {
+		return time.Time{}, 0, false
+	}
+	day, n, ok := matchDate(words, i, now)
+	if !ok {
+		hour, minute, tn, ok := matchTime(words, i, false)
+		if !ok {
+			return time.Time{}, 0, false
+		}
+		due := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+		if !due.After(now) {
+			due = due.AddDate(0, 0, 1)
+		}
+		return due, tn, true
+	}
+	hour, minute := DefaultDueHour, 0
+	if h, m, tn, ok := matchTime(words, i+n, true); ok {
+		hour, minute = h, m
+		n += tn
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, now.Location()), n, true
+}
+
+// matchDate returns the day a date phrase starting at words[i] refers to
+func matchDate(words []string, i int, now time.Time) (time.Time, int, bool) {
+	word := func(k int) string {
+		if i+k >= len(words) {
+			return ""
+		}
+		return strings.ToLower(trimPunct(words[i+k]))
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch w := word(0); {
+	case w == "today" || w == "tonight" || w == "eod":
+		return today, 1, true
+	case w == "tomorrow" || w == "tmrw" || w == "tmr" || w == "tmw":
+		return today.AddDate(0, 0, 1), 1, true
+	case w == "day" && word(1) == "after" && word(2) == "tomorrow":
+		return today.AddDate(0, 0, 2), 3, true
+	case w == "eow" || (w == "end" && word(1) == "of" && word(2) == "week"):
+		return upcoming(today, time.Friday, 0), wordsUsed(w, 3), true
+	case w == "eom" || (w == "end" && word(1) == "of" && word(2) == "month"):
+		return time.Date(today.Year(), today.Month()+1, 0, 0, 0, 0, 0, now.Location()), wordsUsed(w, 3), true
+	case w == "this":
+		if day, ok := weekdays[word(1)]; ok {
+			return upcoming(today, day, 0), 2, true
+		}
+		if word(1) == "week" {
+			return upcoming(today, time.Friday, 0), 2, true
+		}
+	case w == "next":
+		if day, ok := weekdays[word(1)]; ok {
+			return upcoming(today, day, 1), 2, true
+		}
+		switch word(1) {
+		case "week":
+			return upcoming(today, time.Monday, 1), 2, true
+		case "month":
+			return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, now.Location()), 2, true
+		}
+	case w == "in":
+		count, ok := numbers[word(1)]
+		if !ok {
+			count, _ = strconv.Atoi(word(1))
+		}
+		if count <= 0 {
+			break
+		}
+		switch strings.TrimSuffix(word(2), "s") {
+		case "day":
+			return today.AddDate(0, 0, count), 3, true
+		case "week":
+			return today.AddDate(0, 0, 7*count), 3, true
+		case "month":
+			return today.AddDate(0, count, 0), 3, true
+		}
+	}
+
+	w := word(0)
+	if day, ok := weekdays[w]; ok {
+		return upcoming(today, day, 0), 1, true
+	}
+	if m := isoDate.FindStringSubmatch(w); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		if date, ok := validDate(year, time.Month(month), day, now.Location()); ok {
+			return date, 1, true
+		}
+	}
+	// "may 3", "3rd may", "3 may"
+	if month, ok := months[w]; ok {
+		if m := ordinal.FindStringSubmatch(word(1)); m != nil {
+			day, _ := strconv.Atoi(m[1])
+			if date, ok := nextDate(today, month, day); ok {
+				return date, 2, true
+			}
+		}
+	}
+	if m := ordinal.FindStringSubmatch(w); m != nil {
+		if month, ok := months[word(1)]; ok {
+			day, _ := strconv.Atoi(m[1])
+			if date, ok := nextDate(today, month, day); ok {
+				return date, 2, true
+			}
+		}
+	}
+	return time.Time{}, 0, false
+}
+
+// matchTime reads "5pm", "17:30", "noon", optionally after "at". A bare hour
+// ("at 9") is only read after a date; on its own it's as likely a count.
+func matchTime(words []string, i int, afterDate bool) (int, int, int, bool) {
+	n := 0
+	if i < len(words) && strings.EqualFold(words[i], "at") {
+		n = 1
+	}
+	if i+n >= len(words) {
+		return 0, 0, 0, false
+	}
+	w := strings.ToLower(trimPunct(words[i+n]))
+	if w == "noon" {
+		return 12, 0, n + 1, true
+	}
+	m := clock.FindStringSubmatch(w)
+	if m == nil || (m[2] == "" && m[3] == "" && (n == 0 || !afterDate)) {
+		return 0, 0, 0, false
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	if m[3] != "" && (hour < 1 || hour > 12) {
+		return 0, 0, 0, false
+	}
+	switch m[3] {
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, 0, false
+	}
+	return hour, minute, n + 1, true
+}
+
+// upcoming returns the next day that is the given weekday, at least minDays away
+func upcoming(today time.Time, day time.Weekday, minDays int) time.Time {
+	days := (int(day) - int(today.Weekday()) + 7) % 7
+	if days < minDays {
+		days += 7
+	}
+	return today.AddDate(0, 0, days)
+}
+
+// nextDate is the next month/day on or after today, rolling into next year
+func nextDate(today time.Time, month time.Month, day int) (time.Time, bool) {
+	date, ok := validDate(today.Year(), month, day, today.Location())
+	if !ok {
+		return time.Time{}, false
+	}
+	if date.Before(today) {
+		return validDate(today.Year()+1, month, day, today.Location())
+	}
+	return date, true
+}
+
+func validDate(year int, month time.Month, day int, loc *time.Location) (time.Time, bool) {
+	date := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	return date, date.Month() == month && date.Day() == day
+}
+
+// wordsUsed is 1 for an abbreviation such as "eow" and n for the spelled out form
+func wordsUsed(word string, n int) int {
+	if word == "end" {
+		return n
+	}
+	return 1
+}
+
+func trimPunct(word string) string {
+	return strings.TrimRight(word, ",.;:!?)")
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, existing := range list {
+		if strings.EqualFold(existing, value) {
+			return list
+		}
+	}
+	return append(list, value)
+}
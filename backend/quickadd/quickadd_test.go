@@ -0,0 +1,184 @@
+package quickadd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/models"
+)
+
+// wednesday is the fixed "now" of the tests: Wednesday 12 March 2025, 10:00 UTC
+var wednesday = time.Date(2025, time.March, 12, 10, 0, 0, 0, time.UTC)
+
+func at(year int, month time.Month, day, hour, minute int) *time.Time {
+	date := time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	return &date
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		now       time.Time
+		want      Result
+		ambiguous bool
+	}{
+		{
+			name: "plain title",
+			text: "Write the release notes",
+			want: Result{Title: "Write the release notes"},
+		},
+		{
+			name: "bang priority",
+			text: "Fix login !high",
+			want: Result{Title: "Fix login", Priority: models.HIGH},
+		},
+		{
+			name: "p1 priority",
+			text: "p1 Fix login",
+			want: Result{Title: "Fix login", Priority: models.HIGH},
+		},
+		{
+			name: "p3 priority",
+			text: "Tidy the wiki p3",
+			want: Result{Title: "Tidy the wiki", Priority: models.LOW},
+		},
+		{
+			name: "assignees",
+			text: "Review PR @alice @bob @Alice",
+			want: Result{Title: "Review PR", Assignees: []string{"alice", "bob"}},
+		},
+		{
+			name: "labels",
+			text: "Update docs #docs #Release #docs",
+			want: Result{Title: "Update docs", Labels: []string{"docs", "release"}},
+		},
+		{
+			name: "issue numbers are not labels",
+			text: "Close #123",
+			want: Result{Title: "Close #123"},
+		},
+		{
+			name: "tomorrow",
+			text: "Call the bank tomorrow",
+			want: Result{Title: "Call the bank", DueDate: at(2025, time.March, 13, DefaultDueHour, 0)},
+		},
+		{
+			name: "tomorrow with a time",
+			text: "Call the bank tomorrow at 9am",
+			want: Result{Title: "Call the bank", DueDate: at(2025, time.March, 13, 9, 0)},
+		},
+		{
+			name: "next fri is the coming friday",
+			text: "Send invoice next fri",
+			want: Result{Title: "Send invoice", DueDate: at(2025, time.March, 14, DefaultDueHour, 0)},
+		},
+		{
+			name: "next fri on a friday is a week away",
+			text: "Send invoice next fri",
+			now:  time.Date(2025, time.March, 14, 10, 0, 0, 0, time.UTC),
+			want: Result{Title: "Send invoice", DueDate: at(2025, time.March, 21, DefaultDueHour, 0)},
+		},
+		{
+			name: "fri on a friday is today",
+			text: "Send invoice fri",
+			now:  time.Date(2025, time.March, 14, 10, 0, 0, 0, time.UTC),
+			want: Result{Title: "Send invoice", DueDate: at(2025, time.March, 14, DefaultDueHour, 0)},
+		},
+		{
+			name: "in 3 days",
+			text: "Renew passport in 3 days",
+			want: Result{Title: "Renew passport", DueDate: at(2025, time.March, 15, DefaultDueHour, 0)},
+		},
+		{
+			name: "in three days",
+			text: "Renew passport in three days",
+			want: Result{Title: "Renew passport", DueDate: at(2025, time.March, 15, DefaultDueHour, 0)},
+		},
+		{
+			name: "due before a date",
+			text: "Submit report due may 3 at 5pm",
+			want: Result{Title: "Submit report", DueDate: at(2025, time.May, 3, 17, 0)},
+		},
+		{
+			name: "past month and day roll into next year",
+			text: "Book flights jan 5",
+			want: Result{Title: "Book flights", DueDate: at(2026, time.January, 5, DefaultDueHour, 0)},
+		},
+		{
+			name: "sat is only a day after a preposition",
+			text: "Sat exam prep",
+			want: Result{Title: "Sat exam prep"},
+		},
+		{
+			name: "everything at once",
+			text: "Ship v2 !urgent @carol #launch next fri at 3pm",
+			want: Result{
+				Title:     "Ship v2",
+				Priority:  models.HIGH,
+				Assignees: []string{"carol"},
+				Labels:    []string{"launch"},
+				DueDate:   at(2025, time.March, 14, 15, 0),
+			},
+		},
+		{
+			name:      "conflicting priorities keep the first",
+			text:      "Fix login !high p3",
+			want:      Result{Title: "Fix login", Priority: models.HIGH},
+			ambiguous: true,
+		},
+		{
+			name:      "several dates keep the first",
+			text:      "Pay rent tomorrow friday",
+			want:      Result{Title: "Pay rent", DueDate: at(2025, time.March, 13, DefaultDueHour, 0)},
+			ambiguous: true,
+		},
+		{
+			name:      "slash dates fall back to the assistant",
+			text:      "Dentist 3/4",
+			want:      Result{Title: "Dentist 3/4"},
+			ambiguous: true,
+		},
+		{
+			name:      "unreadable next falls back to the assistant",
+			text:      "Plan offsite next quarter",
+			want:      Result{Title: "Plan offsite next quarter"},
+			ambiguous: true,
+		},
+		{
+			name:      "nothing left for the title",
+			text:      "@dave tomorrow !high",
+			want:      Result{Priority: models.HIGH, Assignees: []string{"dave"}, DueDate: at(2025, time.March, 13, DefaultDueHour, 0)},
+			ambiguous: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := tt.now
+			if now.IsZero() {
+				now = wednesday
+			}
+			got := Parse(tt.text, now)
+			if got.Ambiguous() != tt.ambiguous {
+				t.Errorf("Ambiguous() = %v, want %v (ambiguities %q)", got.Ambiguous(), tt.ambiguous, got.Ambiguities)
+			}
+			got.Ambiguities = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.text, describe(got), describe(tt.want))
+			}
+		})
+	}
+}
+
+// describe shows the due date as text in failure messages
+func describe(r Result) interface{} {
+	due := "none"
+	if r.DueDate != nil {
+		due = r.DueDate.Format(time.RFC3339)
+	}
+	return struct {
+		Result
+		Due string
+	}{r, due}
+}
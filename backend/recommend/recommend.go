@@ -0,0 +1,303 @@
+package recommend
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// historyLimit caps how many completed tasks are compared against
+	historyLimit = 1000
+	// similarThreshold is the similarity above which a past task counts as similar
+	similarThreshold = 0.2
+)
+
+// priorityWeight is how much an open task of each priority adds to someone's load
+var priorityWeight = map[models.PriorityType]int{models.HIGH: 3, models.MEDIUM: 2, models.LOW: 1}
+
+// Request describes the task that needs an assignee. Candidates defaults to
+// every user when empty.
+type Request struct {
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	Labels      []string             `json:"labels"`
+	Candidates  []primitive.ObjectID `json:"candidates"`
+	Limit       int                  `json:"limit"`
+}
+
+// Candidate is one ranked suggestion. Score is between 0 and 1.
+type Candidate struct {
+	UserID         primitive.ObjectID          `json:"user_id"`
+	Name           string                      `json:"name"`
+	Email          string                      `json:"email"`
+	Score          float64                     `json:"score"`
+	OpenTasks      int                         `json:"open_tasks"`
+	OpenByPriority map[models.PriorityType]int `json:"open_by_priority"`
+	SimilarTasks   int                         `json:"similar_tasks"`
+	Reasons        []string                    `json:"reasons"`
+}
+
+// Suggest ranks the candidates for a new task by how lightly loaded they are and
+// how similar their completed tasks are to it
+func Suggest(ctx context.Context, req Request) ([]Candidate, error) {
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	users, err := loadUsers(ctx, req.Candidates)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return []Candidate{}, nil
+	}
+	ids := make([]primitive.ObjectID, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+
+	load, err := openLoad(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	history, err := completedTasks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return rank(req, users, load, history), nil
+}
+
+func loadUsers(ctx context.Context, ids []primitive.ObjectID) ([]models.UserRequest, error) {
+	filter := bson.M{}
+	if len(ids) > 0 {
+		filter["_id"] = bson.M{"$in": ids}
+	}
+	cursor, err := database.GetCollection("user").Find(ctx, filter, options.Find().SetProjection(bson.M{"password": 0}))
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch users: %v", err)
+	}
+	var users []models.UserRequest
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, fmt.Errorf("failed to parse users: %v", err)
+	}
+	return users, nil
+}
+
+// openLoad counts each user's open tasks per priority. The $match on assigned_to
+// is served by the assigned_to index.
+func openLoad(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]map[models.PriorityType]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: trash.Live(bson.M{"assigned_to": bson.M{"$in": ids}, "status": bson.M{"$ne": models.COMPLETED}})}},
+		{{Key: "$unwind", Value: "$assigned_to"}},
+		// Other assignees of shared tasks aren't candidates
+		{{Key: "$match", Value: bson.M{"assigned_to": bson.M{"$in": ids}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"user": "$assigned_to", "priority": "$priority"},
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+	cursor, err := database.GetCollection("task").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("could not count open tasks: %v", err)
+	}
+	var rows []struct {
+		ID struct {
+			User     primitive.ObjectID  `bson:"user"`
+			Priority models.PriorityType `bson:"priority"`
+		} `bson:"_id"`
+		Count int `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse open task counts: %v", err)
+	}
+	load := map[primitive.ObjectID]map[models.PriorityType]int{}
+	for _, row := range rows {
+		if load[row.ID.User] == nil {
+			load[row.ID.User] = map[models.PriorityType]int{}
+		}
+		load[row.ID.User][row.ID.Priority] += row.Count
+	}
+	return load, nil
+}
+
+func completedTasks(ctx context.Context, ids []primitive.ObjectID) ([]models.Task, error) {
+	opts := options.Find().
+		SetSort(bson.M{"updated_at": -1}).
+		SetLimit(historyLimit).
+		SetProjection(bson.M{"title": 1, "description": 1, "labels": 1, "assigned_to": 1})
+	cursor, err := database.GetCollection("task").Find(ctx, trash.Live(bson.M{"assigned_to": bson.M{"$in": ids}, "status": models.COMPLETED}), opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch completed tasks: %v", err)
+	}
+	var tasks []models.Task
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to parse completed tasks: %v", err)
+	}
+	return tasks, nil
+}
+
+// rank scores every user: half for having little open work (weighted by priority)
+// and half for having done similar work before
+func rank(req Request, users []models.UserRequest, load map[primitive.ObjectID]map[models.PriorityType]int, history []models.Task) []Candidate {
+	target := terms(req.Title + " " + req.Description + " " + strings.Join(req.Labels, " "))
+
+	type match struct {
+		score float64
+		title string
+	}
+	matches := map[primitive.ObjectID][]match{}
+	for _, task := range history {
+		score := similarity(target, terms(task.Title+" "+task.Description+" "+strings.Join(task.Labels, " ")))
+		if score == 0 {
+			continue
+		}
+		for _, userID := range task.AssignedTo {
+			matches[userID] = append(matches[userID], match{score, task.Title})
+		}
+	}
+
+	candidates := make([]Candidate, len(users))
+	weights := make([]int, len(users))
+	similarities := make([]float64, len(users))
+	maxWeight, maxSimilarity := 0, 0.0
+	for i, user := range users {
+		byPriority := load[user.ID]
+		if byPriority == nil {
+			byPriority = map[models.PriorityType]int{}
+		}
+		open := 0
+		for priority, count := range byPriority {
+			open += count
+			weights[i] += count * priorityWeight[priority]
+		}
+
+		userMatches := matches[user.ID]
+		sort.Slice(userMatches, func(a, b int) bool { return userMatches[a].score > userMatches[b].score })
+		similar := 0
+		var examples []string
+		// The best three matches make up the similarity, so one lucky hit doesn't dominate
+		for j, m := range userMatches {
+			if j < 3 {
+				similarities[i] += m.score / 3
+			}
+			if m.score >= similarThreshold {
+				similar++
+				if len(examples) < 2 {
+					examples = append(examples, fmt.Sprintf("%q", m.title))
+				}
+			}
+		}
+
+		candidates[i] = Candidate{
+			UserID:         user.ID,
+			Name:           user.Name,
+			Email:          user.Email,
+			OpenTasks:      open,
+			OpenByPriority: byPriority,
+			SimilarTasks:   similar,
+			Reasons:        []string{loadReason(open, byPriority), historyReason(similar, examples)},
+		}
+		if weights[i] > maxWeight {
+			maxWeight = weights[i]
+		}
+		if similarities[i] > maxSimilarity {
+			maxSimilarity = similarities[i]
+		}
+	}
+
+	for i := range candidates {
+		loadScore := 1.0
+		if maxWeight > 0 {
+			loadScore = 1 - float64(weights[i])/float64(maxWeight)
+		}
+		experienceScore := 0.0
+		if maxSimilarity > 0 {
+			experienceScore = similarities[i] / maxSimilarity
+		}
+		candidates[i].Score = math.Round((0.5*loadScore+0.5*experienceScore)*1000) / 1000
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		if candidates[a].Score != candidates[b].Score {
+			return candidates[a].Score > candidates[b].Score
+		}
+		return candidates[a].OpenTasks < candidates[b].OpenTasks
+	})
+	if req.Limit > 0 && len(candidates) > req.Limit {
+		candidates = candidates[:req.Limit]
+	}
+	return candidates
+}
+
+func loadReason(open int, byPriority map[models.PriorityType]int) string {
+	if open == 0 {
+		return "No open tasks"
+	}
+	var parts []string
+	for _, priority := range []models.PriorityType{models.HIGH, models.MEDIUM, models.LOW} {
+		if count := byPriority[priority]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, priority))
+		}
+	}
+	noun := "tasks"
+	if open == 1 {
+		noun = "task"
+	}
+	return fmt.Sprintf("%d open %s (%s)", open, noun, strings.Join(parts, ", "))
+}
+
+func historyReason(similar int, examples []string) string {
+	if similar == 0 {
+		return "No similar completed tasks"
+	}
+	noun := "tasks"
+	if similar == 1 {
+		noun = "task"
+	}
+	return fmt.Sprintf("Completed %d similar %s, e.g. %s", similar, noun, strings.Join(examples, ", "))
+}
+
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true,
+	"that": true, "this": true, "are": true, "was": true, "our": true, "all": true,
+	"add": true, "new": true, "fix": true, "task": true, "update": true,
+}
+
+// terms splits text into lower-cased words, dropping short and common ones
+func terms(text string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		if len(word) < 3 || stopWords[word] {
+			continue
+		}
+		set[strings.TrimSuffix(word, "s")] = true
+	}
+	return set
+}
+
+// similarity is the Jaccard index of two term sets
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for term := range a {
+		if b[term] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,166 @@
+// Package speech turns dictated audio into text for the assistant, through
+// Gemini or a Whisper-compatible transcription server.
+package speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/genai"
+)
+
+// MaxAudioBytes is the largest recording accepted
+const MaxAudioBytes = 4 << 20
+
+// Supported audio formats
+const (
+	WebM = "audio/webm"
+	Ogg  = "audio/ogg"
+	WAV  = "audio/wav"
+)
+
+// ErrNoSpeech is returned when a recording has no words in it
+var ErrNoSpeech = errors.New("no speech was recognized in the recording")
+
+// Transcriber turns audio in one of the supported formats into text
+type Transcriber interface {
+	Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error)
+}
+
+// New returns the transcriber chosen by SPEECH_PROVIDER: "gemini" (the
+// default) or "whisper", which posts to WHISPER_URL
+func New() (Transcriber, error) {
+	switch provider := strings.ToLower(os.Getenv("SPEECH_PROVIDER")); provider {
+	case "", "gemini":
+		return Gemini{}, nil
+	case "whisper":
+		url := os.Getenv("WHISPER_URL")
+		if url == "" {
+			return nil, fmt.Errorf("WHISPER_URL is not set")
+		}
+		model := os.Getenv("WHISPER_MODEL")
+		if model == "" {
+			model = "whisper-1"
+		}
+		return &Whisper{URL: url, Model: model, APIKey: os.Getenv("WHISPER_API_KEY")}, nil
+	default:
+		return nil, fmt.Errorf("unknown SPEECH_PROVIDER %q", provider)
+	}
+}
+
+// Detect checks a recording's container from its first bytes and returns its
+// MIME type. The declared type (e.g. "audio/webm;codecs=opus") is only used
+// in the error message.
+func Detect(audio []byte, declared string) (string, error) {
+	switch {
+	case len(audio) == 0:
+		return "", fmt.Errorf("the recording is empty")
+	case len(audio) > MaxAudioBytes:
+		return "", fmt.Errorf("the recording is larger than %d MB", MaxAudioBytes>>20)
+	case bytes.HasPrefix(audio, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return WebM, nil
+	case bytes.HasPrefix(audio, []byte("OggS")):
+		return Ogg, nil
+	case len(audio) >= 12 && bytes.Equal(audio[:4], []byte("RIFF")) && bytes.Equal(audio[8:12], []byte("WAVE")):
+		return WAV, nil
+	}
+	if declared != "" {
+		return "", fmt.Errorf("unsupported audio format %s; send webm/opus, ogg/opus or wav", declared)
+	}
+	return "", fmt.Errorf("unsupported audio format; send webm/opus, ogg/opus or wav")
+}
+
+// Transcribe detects the recording's format and transcribes it with the
+// configured provider
+func Transcribe(ctx context.Context, audio []byte, declared string) (string, error) {
+	mimeType, err := Detect(audio, declared)
+	if err != nil {
+		return "", err
+	}
+	transcriber, err := New()
+	if err != nil {
+		return "", err
+	}
+	transcript, err := transcriber.Transcribe(ctx, audio, mimeType)
+	if err != nil {
+		return "", err
+	}
+	if transcript = strings.TrimSpace(transcript); transcript == "" {
+		return "", ErrNoSpeech
+	}
+	return transcript, nil
+}
+
+// Gemini transcribes with the model configured for the "transcribe" use
+type Gemini struct{}
+
+func (Gemini) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	return genai.Transcribe(ctx, audio, mimeType)
+}
+
+// Whisper posts recordings to an OpenAI-compatible transcription endpoint,
+// such as a local whisper.cpp or faster-whisper server
+type Whisper struct {
+	URL    string
+	Model  string
+	APIKey string
+	Client *http.Client
+}
+
+var extensions = map[string]string{WebM: "webm", Ogg: "ogg", WAV: "wav"}
+
+func (w *Whisper) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "recording."+extensions[mimeType])
+	if err != nil {
+		return "", err
+	}
+	part.Write(audio)
+	form.WriteField("model", w.Model)
+	form.WriteField("response_format", "json")
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if w.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+w.APIKey)
+	}
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 2 * time.Minute}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("transcription request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read transcription: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("transcription server returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("failed to parse transcription: %v", err)
+	}
+	return result.Text, nil
+}
@@ -0,0 +1,88 @@
+// Package storage keeps file contents, such as task attachments, in a blob
+// store: the local filesystem or an S3-compatible service like MinIO.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned for keys with no blob
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores blobs under slash-separated keys
+type BlobStore interface {
+	// Put stores size bytes from r under key, replacing any existing blob
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get streams length bytes of the blob starting at offset. A negative
+	// length reads to the end.
+	Get(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	// Delete removes the blob. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	store     BlobStore
+	storeErr  error
+	storeOnce sync.Once
+)
+
+// Default returns the store configured by BLOB_STORE: "fs" (the default),
+// keeping blobs under BLOB_DIR, or "s3" using the S3_* variables
+func Default() (BlobStore, error) {
+	storeOnce.Do(func() {
+		store, storeErr = New()
+	})
+	return store, storeErr
+}
+
+// New builds a store from the environment
+func New() (BlobStore, error) {
+	switch kind := strings.ToLower(os.Getenv("BLOB_STORE")); kind {
+	case "", "fs":
+		dir := os.Getenv("BLOB_DIR")
+		if dir == "" {
+			dir = "data/blobs"
+		}
+		return NewFSStore(dir)
+	case "s3":
+		s3 := &S3Store{
+			Endpoint:  strings.TrimRight(os.Getenv("S3_ENDPOINT"), "/"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		}
+		if s3.Endpoint == "" || s3.Bucket == "" {
+			return nil, fmt.Errorf("S3_ENDPOINT and S3_BUCKET must be set")
+		}
+		if s3.Region == "" {
+			s3.Region = "us-east-1"
+		}
+		if err := s3.EnsureBucket(context.Background()); err != nil {
+			log.Printf("Could not create bucket %s: %v", s3.Bucket, err)
+		}
+		return s3, nil
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q", kind)
+	}
+}
+
+// validKey rejects keys that could escape the store's root
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
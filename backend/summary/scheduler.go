@@ -0,0 +1,82 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/notification"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Start posts the daily standup to the inbox of every user who opted in, at
+// SUMMARY_HOUR (default 8) server time
+func Start(ctx context.Context) {
+	go runDailyJob(ctx, summaryHour())
+}
+
+func summaryHour() int {
+	hour, err := strconv.Atoi(os.Getenv("SUMMARY_HOUR"))
+	if err != nil || hour < 0 || hour > 23 {
+		return 8
+	}
+	return hour
+}
+
+func runDailyJob(ctx context.Context, hour int) {
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			if err := PostDailySummaries(ctx); err != nil {
+				log.Printf("Summary: daily run failed: %v", err)
+			}
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// PostDailySummaries writes each opted-in user's standup for the last day to their inbox
+func PostDailySummaries(ctx context.Context) error {
+	cursor, err := database.GetCollection("user").Find(ctx, bson.M{"daily_summary": true})
+	if err != nil {
+		return fmt.Errorf("could not fetch users: %v", err)
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return fmt.Errorf("failed to parse users: %v", err)
+	}
+
+	for _, user := range users {
+		standup, activity, err := Generate(ctx, Request{UserID: user.ID, Scope: models.SummaryScopeMe, Period: models.SummaryPeriodDay})
+		if err != nil {
+			log.Printf("Summary: failed for user %s: %v", user.ID.Hex(), err)
+			continue
+		}
+		// Nothing happened and nothing is open, so there's nothing worth posting
+		if activity.Empty() {
+			continue
+		}
+		_, err = notification.AddToInbox(ctx, models.Notification{
+			UserID:  user.ID,
+			Event:   models.EventStandup,
+			Message: Format(standup),
+		})
+		if err != nil {
+			log.Printf("Summary: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/genai"
+	"github.com/Atif-27/ai-task-manager/models"
+	"github.com/Atif-27/ai-task-manager/trash"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BlockedLabel marks a task as blocked; there is no blocked status
+const BlockedLabel = "blocked"
+
+// Request selects whose tasks are summarised and over which period. Team scope
+// covers the given workspace, or every workspace the user belongs to.
+type Request struct {
+	UserID      primitive.ObjectID
+	Scope       string
+	Period      string
+	WorkspaceID *primitive.ObjectID
+	Now         time.Time
+}
+
+// Activity is the task activity a summary is written from
+type Activity struct {
+	Since      time.Time     `json:"since"`
+	Completed  []models.Task `json:"completed"`
+	InProgress []models.Task `json:"in_progress"`
+	Blocked    []models.Task `json:"blocked"`
+	Overdue    []models.Task `json:"overdue"`
+	Created    []models.Task `json:"created"`
+}
+
+func (a Activity) Empty() bool {
+	return len(a.Completed)+len(a.InProgress)+len(a.Blocked)+len(a.Overdue)+len(a.Created) == 0
+}
+
+func (r *Request) Validate() error {
+	if r.Scope == "" {
+		r.Scope = models.SummaryScopeMe
+	}
+	if r.Period == "" {
+		r.Period = models.SummaryPeriodDay
+	}
+	if r.Scope != models.SummaryScopeMe && r.Scope != models.SummaryScopeTeam {
+		return fmt.Errorf("scope must be me or team")
+	}
+	if r.Period != models.SummaryPeriodDay && r.Period != models.SummaryPeriodWeek {
+		return fmt.Errorf("period must be day or week")
+	}
+	if r.Now.IsZero() {
+		r.Now = time.Now()
+	}
+	return nil
+}
+
+func (r Request) since() time.Time {
+	if r.Period == models.SummaryPeriodWeek {
+		return r.Now.AddDate(0, 0, -7)
+	}
+	return r.Now.AddDate(0, 0, -1)
+}
+
+func (r Request) periodName() string {
+	if r.Period == models.SummaryPeriodWeek {
+		return "the last week"
+	}
+	return "the last day"
+}
+
+// filter selects the tasks in scope: assigned to the user, or in their workspaces
+func (r Request) filter(ctx context.Context) (bson.M, error) {
+	if r.Scope == models.SummaryScopeMe {
+		return bson.M{"assigned_to": r.UserID}, nil
+	}
+	if r.WorkspaceID != nil {
+		return bson.M{"workspace_id": *r.WorkspaceID}, nil
+	}
+	cursor, err := database.GetCollection("workspace").Find(ctx, bson.M{"members": r.UserID})
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch workspaces: %v", err)
+	}
+	var workspaces []models.Workspace
+	if err := cursor.All(ctx, &workspaces); err != nil {
+		return nil, fmt.Errorf("failed to parse workspaces: %v", err)
+	}
+	ids := make([]primitive.ObjectID, len(workspaces))
+	for i, workspace := range workspaces {
+		ids[i] = workspace.ID
+	}
+	return bson.M{"workspace_id": bson.M{"$in": ids}}, nil
+}
+
+// Gather loads the tasks in scope and sorts them into the standup sections
+func Gather(ctx context.Context, req Request) (Activity, error) {
+	if err := req.Validate(); err != nil {
+		return Activity{}, err
+	}
+	since := req.since()
+	filter, err := req.filter(ctx)
+	if err != nil {
+		return Activity{}, err
+	}
+	// Completed tasks untouched in the period can't be relevant
+	filter["$or"] = []bson.M{{"status": bson.M{"$ne": models.COMPLETED}}, {"updated_at": bson.M{"$gte": since}}}
+
+	cursor, err := database.GetCollection("task").Find(ctx, trash.Live(filter))
+	if err != nil {
+		return Activity{}, fmt.Errorf("could not fetch tasks: %v", err)
+	}
+	var tasks []models.Task
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return Activity{}, fmt.Errorf("failed to parse tasks: %v", err)
+	}
+
+	activity := Activity{Since: since}
+	for _, task := range tasks {
+		switch {
+		case task.Status == models.COMPLETED:
+			if completedSince(task, since) {
+				activity.Completed = append(activity.Completed, task)
+			}
+		case hasLabel(task, BlockedLabel):
+			activity.Blocked = append(activity.Blocked, task)
+		case task.Status == models.INPROGRESS:
+			activity.InProgress = append(activity.InProgress, task)
+		}
+		if task.Status != models.COMPLETED && task.DueDate != nil && task.DueDate.Before(req.Now) {
+			activity.Overdue = append(activity.Overdue, task)
+		}
+		if !task.CreatedAt.Before(since) {
+			activity.Created = append(activity.Created, task)
+		}
+	}
+	return activity, nil
+}
+
+// Generate gathers the activity and has the assistant write the standup. Without
+// any activity it returns an empty standup instead of calling the model.
+func Generate(ctx context.Context, req Request) (models.Standup, Activity, error) {
+	if err := req.Validate(); err != nil {
+		return models.Standup{}, Activity{}, err
+	}
+	activity, err := Gather(ctx, req)
+	if err != nil {
+		return models.Standup{}, Activity{}, err
+	}
+	if activity.Empty() {
+		return models.Standup{Summary: "No task activity in " + req.periodName() + ".", Done: []string{}, Doing: []string{}, Blockers: []string{}, Risks: []string{}}, activity, nil
+	}
+	ctx = aiconfig.WithScope(ctx, &req.UserID, req.WorkspaceID)
+	standup, err := genai.GenerateStandup(ctx, activity.Describe(req.Now), req.periodName())
+	if err != nil {
+		return models.Standup{}, activity, err
+	}
+	return standup, activity, nil
+}
+
+// Describe renders the activity as the plain text the model is given
+func (a Activity) Describe(now time.Time) string {
+	var b strings.Builder
+	section := func(name string, tasks []models.Task) {
+		fmt.Fprintf(&b, "%s (%d):\n", name, len(tasks))
+		for _, task := range tasks {
+			fmt.Fprintf(&b, "- %q priority=%s status=%s", task.Title, task.Priority, task.Status)
+			if task.DueDate != nil {
+				fmt.Fprintf(&b, " due=%s", task.DueDate.Format("2006-01-02"))
+			}
+			if len(task.Labels) > 0 {
+				fmt.Fprintf(&b, " labels=%s", strings.Join(task.Labels, ","))
+			}
+			for _, change := range task.StatusHistory {
+				if !change.At.Before(a.Since) {
+					fmt.Fprintf(&b, " moved_to_%s=%s", change.Status, change.At.Format("2006-01-02 15:04"))
+				}
+			}
+			if description := firstLine(task.Description); description != "" {
+				fmt.Fprintf(&b, "\n  %s", description)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Today is %s.\n\n", now.Format("Monday 2006-01-02"))
+	section("Completed", a.Completed)
+	section("In progress", a.InProgress)
+	section("Blocked", a.Blocked)
+	section("Overdue", a.Overdue)
+	section("Created", a.Created)
+	return b.String()
+}
+
+// Format renders a standup as the plain text posted to the inbox
+func Format(standup models.Standup) string {
+	var b strings.Builder
+	b.WriteString(standup.Summary)
+	for _, section := range []struct {
+		name  string
+		items []string
+	}{{"Done", standup.Done}, {"Doing", standup.Doing}, {"Blockers", standup.Blockers}, {"Risks", standup.Risks}} {
+		if len(section.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\n%s:", section.name)
+		for _, item := range section.items {
+			b.WriteString("\n- " + item)
+		}
+	}
+	return b.String()
+}
+
+// completedSince reports whether the task was completed after since, falling back
+// to its last update for tasks without status history
+func completedSince(task models.Task, since time.Time) bool {
+	for i := len(task.StatusHistory) - 1; i >= 0; i-- {
+		if task.StatusHistory[i].Status == models.COMPLETED {
+			return !task.StatusHistory[i].At.Before(since)
+		}
+	}
+	return !task.UpdatedAt.Before(since)
+}
+
+func hasLabel(task models.Task, label string) bool {
+	for _, l := range task.Labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	if runes := []rune(s); len(runes) > 200 {
+		s = string(runes[:200]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,105 @@
+// Package trash keeps deleted tasks recoverable: deleting a task only marks it
+// with deleted_at, and a background job removes it for good once the
+// retention period has passed.
+package trash
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/attachment"
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// defaultRetentionDays applies when TRASH_RETENTION_DAYS is unset
+const defaultRetentionDays = 30
+
+// Live adds the condition that leaves out trashed tasks to a task filter and
+// returns it. Every query for tasks that are in use goes through here.
+func Live(filter bson.M) bson.M {
+	// Matches tasks without the field as well as restored ones
+	filter["deleted_at"] = nil
+	return filter
+}
+
+// Trashed adds the condition that only matches trashed tasks to a task filter
+func Trashed(filter bson.M) bson.M {
+	filter["deleted_at"] = bson.M{"$ne": nil}
+	return filter
+}
+
+// Retention is how long a task stays in the trash, from TRASH_RETENTION_DAYS
+func Retention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = defaultRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// PurgeAt is when a task deleted at deletedAt is removed for good
+func PurgeAt(deletedAt time.Time) time.Time {
+	return deletedAt.Add(Retention())
+}
+
+// Start runs the purge job in the background
+func Start(ctx context.Context) {
+	go runPurgeJob(ctx, time.Hour)
+}
+
+func runPurgeJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := Purge(ctx, time.Now().Add(-Retention())); err != nil {
+			log.Printf("Trash: purge failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("Trash: purged %d tasks", purged)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Purge permanently removes the tasks trashed before cutoff, with their
+// attachments, and returns how many were removed. Attachments are only removed
+// once their task is, so a task restored during the purge keeps them.
+func Purge(ctx context.Context, cutoff time.Time) (int64, error) {
+	tasks := database.GetCollection("task")
+	cursor, err := tasks.Find(ctx, bson.M{"deleted_at": bson.M{"$lt": cutoff}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, fmt.Errorf("could not fetch trashed tasks: %v", err)
+	}
+	var expired []models.Task
+	if err := cursor.All(ctx, &expired); err != nil {
+		return 0, fmt.Errorf("failed to parse trashed tasks: %v", err)
+	}
+
+	var purged int64
+	for _, task := range expired {
+		// Re-check deleted_at in case the task was restored meanwhile
+		result, err := tasks.DeleteOne(ctx, bson.M{"_id": task.ID, "deleted_at": bson.M{"$lt": cutoff}})
+		if err != nil {
+			return purged, fmt.Errorf("could not delete task %s: %v", task.ID.Hex(), err)
+		}
+		if result.DeletedCount == 0 {
+			continue
+		}
+		purged++
+		if err := attachment.DeleteForTask(ctx, task.ID); err != nil {
+			log.Printf("Trash: could not delete attachments of purged task %s: %v", task.ID.Hex(), err)
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,172 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/aiconfig"
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// QuotaError is returned by Check when a quota is used up. Message is meant to
+// be shown to the user as is.
+type QuotaError struct {
+	Message string
+}
+
+func (e *QuotaError) Error() string {
+	return e.Message
+}
+
+// Record stores the tokens of one model call for the user and workspace of
+// ctx's scope. Failures are only logged; metering must not break the AI call.
+func Record(ctx context.Context, use string, model string, promptTokens, outputTokens, totalTokens int64) {
+	// Nothing to record into when running without a database (the eval harness)
+	if database.DB == nil {
+		return
+	}
+	scope := aiconfig.ScopeFrom(ctx)
+	if totalTokens == 0 {
+		totalTokens = promptTokens + outputTokens
+	}
+	entry := models.AIUsage{
+		ID:           primitive.NewObjectID(),
+		UserID:       scope.UserID,
+		WorkspaceID:  scope.WorkspaceID,
+		Use:          use,
+		Model:        model,
+		PromptTokens: promptTokens,
+		OutputTokens: outputTokens,
+		TotalTokens:  totalTokens,
+		CostUSD:      Cost(aiconfig.Resolve(aiconfig.WithScope(ctx, nil, nil)).Costs, model, promptTokens, outputTokens),
+		CreatedAt:    time.Now(),
+	}
+	// Use a fresh context so usage is kept even if the request was cancelled
+	if _, err := database.GetCollection("ai_usage").InsertOne(context.Background(), entry); err != nil {
+		log.Printf("Could not record AI usage: %v", err)
+	}
+}
+
+// Cost prices a call with the cost table. Models missing from the table cost 0.
+func Cost(costs map[string]models.AIModelCost, model string, promptTokens, outputTokens int64) float64 {
+	cost, ok := costs[model]
+	if !ok {
+		return 0
+	}
+	return (float64(promptTokens)*cost.InputPerMillion + float64(outputTokens)*cost.OutputPerMillion) / 1e6
+}
+
+// Check returns a *QuotaError when the user or workspace of ctx's scope has used
+// up a daily or monthly quota
+func Check(ctx context.Context) error {
+	scope := aiconfig.ScopeFrom(ctx)
+	quotas := aiconfig.Resolve(ctx).Quotas
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	checks := []struct {
+		field  string
+		id     *primitive.ObjectID
+		limit  *int64
+		since  time.Time
+		period string
+		who    string
+	}{
+		{"user_id", scope.UserID, quotas.UserDaily, day, "daily", "your"},
+		{"user_id", scope.UserID, quotas.UserMonthly, month, "monthly", "your"},
+		{"workspace_id", scope.WorkspaceID, quotas.WorkspaceDaily, day, "daily", "this workspace's"},
+		{"workspace_id", scope.WorkspaceID, quotas.WorkspaceMonthly, month, "monthly", "this workspace's"},
+	}
+	for _, check := range checks {
+		if check.id == nil || check.limit == nil || *check.limit == 0 {
+			continue
+		}
+		used, err := Used(ctx, check.field, *check.id, check.since)
+		if err != nil {
+			// Don't block the assistant because usage couldn't be counted
+			log.Printf("Could not check AI quota: %v", err)
+			return nil
+		}
+		if used >= *check.limit {
+			resets := "at midnight UTC"
+			if check.period == "monthly" {
+				resets = "on the 1st of next month (UTC)"
+			}
+			return &QuotaError{Message: fmt.Sprintf("You've reached %s %s AI limit of %d tokens. It resets %s.", check.who, check.period, *check.limit, resets)}
+		}
+	}
+	return nil
+}
+
+// Used sums the tokens recorded for a user_id or workspace_id since a time
+func Used(ctx context.Context, field string, id primitive.ObjectID, since time.Time) (int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{field: id, "created_at": bson.M{"$gte": since}}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "tokens": bson.M{"$sum": "$total_tokens"}}}},
+	}
+	cursor, err := database.GetCollection("ai_usage").Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, fmt.Errorf("could not sum AI usage: %v", err)
+	}
+	var rows []struct {
+		Tokens int64 `bson:"tokens"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return 0, fmt.Errorf("failed to parse AI usage: %v", err)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return rows[0].Tokens, nil
+}
+
+// Report groups the usage matching match by user, workspace, model, use or day
+func Report(ctx context.Context, match bson.M, groupBy string) ([]models.AIUsageTotal, error) {
+	var key interface{}
+	switch groupBy {
+	case "user":
+		key = bson.M{"$toString": "$user_id"}
+	case "workspace":
+		key = bson.M{"$toString": "$workspace_id"}
+	case "model":
+		key = "$model"
+	case "use":
+		key = "$use"
+	case "day":
+		key = bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$created_at"}}
+	default:
+		return nil, fmt.Errorf("group_by must be user, workspace, model, use or day")
+	}
+	sort := bson.D{{Key: "total_tokens", Value: -1}}
+	if groupBy == "day" {
+		sort = bson.D{{Key: "_id", Value: 1}}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           key,
+			"requests":      bson.M{"$sum": 1},
+			"prompt_tokens": bson.M{"$sum": "$prompt_tokens"},
+			"output_tokens": bson.M{"$sum": "$output_tokens"},
+			"total_tokens":  bson.M{"$sum": "$total_tokens"},
+			"cost_usd":      bson.M{"$sum": "$cost_usd"},
+		}}},
+		{{Key: "$sort", Value: sort}},
+	}
+	cursor, err := database.GetCollection("ai_usage").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("could not aggregate AI usage: %v", err)
+	}
+	totals := []models.AIUsageTotal{}
+	if err := cursor.All(ctx, &totals); err != nil {
+		return nil, fmt.Errorf("failed to parse AI usage: %v", err)
+	}
+	return totals, nil
+}
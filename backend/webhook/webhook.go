@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/Atif-27/ai-task-manager/database"
+	"github.com/Atif-27/ai-task-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	maxAttempts    = 6
+	baseRetryDelay = time.Minute
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Sign returns the "sha256=<hex>" HMAC-SHA256 signature of body using secret.
+// Receivers recompute it over the raw request body and compare in constant time.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatch queues payload for every active subscription of the workspace that
+// listens to event. Tasks outside a workspace have no webhooks.
+func Dispatch(ctx context.Context, workspaceID *primitive.ObjectID, event string, payload interface{}) {
+	if workspaceID == nil {
+		return
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Webhook: failed to encode %s payload: %v", event, err)
+		return
+	}
+
+	filter := bson.M{"workspace_id": *workspaceID, "active": true, "events": event}
+	cursor, err := database.GetCollection("webhook").Find(ctx, filter)
+	if err != nil {
+		log.Printf("Webhook: could not fetch subscriptions: %v", err)
+		return
+	}
+	var subscriptions []models.WebhookSubscription
+	if err := cursor.All(ctx, &subscriptions); err != nil {
+		log.Printf("Webhook: failed to parse subscriptions: %v", err)
+		return
+	}
+
+	for _, sub := range subscriptions {
+		delivery := newDelivery(sub.ID, sub.WorkspaceID, event, string(body))
+		if _, err := database.GetCollection("webhook_delivery").InsertOne(ctx, delivery); err != nil {
+			log.Printf("Webhook: failed to queue delivery for %s: %v", sub.URL, err)
+		}
+	}
+}
+
+// Redeliver queues a fresh copy of an earlier delivery, whatever its outcome was
+func Redeliver(ctx context.Context, original models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	delivery := newDelivery(original.SubscriptionID, original.WorkspaceID, original.Event, original.Payload)
+	delivery.RedeliveryOf = &original.ID
+	if _, err := database.GetCollection("webhook_delivery").InsertOne(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to queue redelivery: %v", err)
+	}
+	return &delivery, nil
+}
+
+func newDelivery(subscriptionID, workspaceID primitive.ObjectID, event string, payload string) models.WebhookDelivery {
+	now := time.Now()
+	return models.WebhookDelivery{
+		ID:             primitive.NewObjectID(),
+		SubscriptionID: subscriptionID,
+		WorkspaceID:    workspaceID,
+		Event:          event,
+		Payload:        payload,
+		Status:         models.DeliveryPending,
+		Attempts:       []models.DeliveryAttempt{},
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}
+}
+
+// Start launches the delivery worker
+func Start(ctx context.Context) {
+	go runWorker(ctx, 5*time.Second)
+}
+
+func runWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		processDeliveries(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// retryDelay is the exponential backoff applied after the given number of failed attempts
+func retryDelay(attempts int) time.Duration {
+	return baseRetryDelay * time.Duration(math.Pow(2, float64(attempts-1)))
+}
+
+// processDeliveries sends every due delivery until none are left
+func processDeliveries(ctx context.Context) {
+	deliveries := database.GetCollection("webhook_delivery")
+	for {
+		now := time.Now()
+		filter := bson.M{"status": models.DeliveryPending, "next_attempt_at": bson.M{"$lte": now}}
+		// Pushing next_attempt_at forward claims the delivery so another worker skips it
+		claim := bson.M{"$set": bson.M{"next_attempt_at": now.Add(2 * httpClient.Timeout)}}
+		opts := options.FindOneAndUpdate().SetSort(bson.M{"next_attempt_at": 1})
+
+		var delivery models.WebhookDelivery
+		err := deliveries.FindOneAndUpdate(ctx, filter, claim, opts).Decode(&delivery)
+		if err == mongo.ErrNoDocuments {
+			return
+		}
+		if err != nil {
+			log.Printf("Webhook: failed to claim delivery: %v", err)
+			return
+		}
+
+		var sub models.WebhookSubscription
+		err = database.GetCollection("webhook").FindOne(ctx, bson.M{"_id": delivery.SubscriptionID}).Decode(&sub)
+		if err != nil || !sub.Active {
+			// The subscription was removed or disabled after the event was queued
+			deliveries.UpdateByID(ctx, delivery.ID, bson.M{"$set": bson.M{"status": models.DeliveryFailed}})
+			continue
+		}
+
+		attempt := send(ctx, sub, delivery)
+		set := bson.M{}
+		switch {
+		case attempt.Error == "":
+			set["status"] = models.DeliverySucceeded
+		case len(delivery.Attempts)+1 >= maxAttempts:
+			set["status"] = models.DeliveryFailed
+		default:
+			set["next_attempt_at"] = time.Now().Add(retryDelay(len(delivery.Attempts) + 1))
+		}
+		update := bson.M{"$set": set, "$push": bson.M{"attempts": attempt}}
+		if _, err := deliveries.UpdateByID(ctx, delivery.ID, update); err != nil {
+			log.Printf("Webhook: failed to record delivery %s: %v", delivery.ID.Hex(), err)
+		}
+	}
+}
+
+// send POSTs the signed payload once. Any non-2xx response counts as a failure.
+func send(ctx context.Context, sub models.WebhookSubscription, delivery models.WebhookDelivery) models.DeliveryAttempt {
+	body := []byte(delivery.Payload)
+	attempt := models.DeliveryAttempt{At: time.Now()}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ai-task-manager-webhooks")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID.Hex())
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, body))
+
+	resp, err := httpClient.Do(req)
+	attempt.DurationMs = time.Since(attempt.At).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		attempt.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return attempt
+}
@@ -2,12 +2,18 @@ package ws
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 
+	"github.com/Atif-27/ai-task-manager/aiconfig"
 	"github.com/Atif-27/ai-task-manager/genai"
+	"github.com/Atif-27/ai-task-manager/guard"
+	"github.com/Atif-27/ai-task-manager/speech"
+	"github.com/Atif-27/ai-task-manager/usage"
 	"github.com/gofiber/websocket/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -16,16 +22,36 @@ const (
 	MessageTypeChat        = "chat"
 	MessageTypeAIRequest   = "ai_request"
 	MessageTypeAIResponse  = "ai_response"
+	MessageTypeAudioChunk  = "audio_chunk"
+	MessageTypeTranscript  = "transcript"
 	MessageTypeTaskCreated = "task_created"
 	MessageTypeError       = "error"
+
+	MessageTypeNotification = "notification"
 )
 
 type AIConversationRequest struct {
 	Message string `json:"message"`
 }
 
+// AudioChunk is part of a dictated message. Data is base64; the chunks are
+// joined in order and transcribed once a chunk has Final set.
+type AudioChunk struct {
+	Data     string `json:"data"`
+	MimeType string `json:"mime_type"`
+	Final    bool   `json:"final"`
+}
+
+type TranscriptResponse struct {
+	Transcript string `json:"transcript"`
+}
+
 type AIConversationResponse struct {
 	Message string `json:"message"`
+	// Transcript is the dictated message the response answers
+	Transcript string `json:"transcript,omitempty"`
+	// Steps are the tool calls the assistant made to answer
+	Steps []genai.Step `json:"steps,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -38,35 +64,66 @@ var (
 	conversationMutex   = &sync.Mutex{}
 )
 
+// audioBuffer collects the chunks of a dictated message on one connection
+type audioBuffer struct {
+	data     []byte
+	mimeType string
+}
+
+var (
+	audioBuffers = make(map[*websocket.Conn]*audioBuffer)
+	audioMutex   = &sync.Mutex{}
+)
+
+// startConversation marks the user as having a conversation in progress,
+// reporting false if one already is
+func startConversation(c *websocket.Conn, userIDStr string) bool {
+	conversationMutex.Lock()
+	defer conversationMutex.Unlock()
+	if activeConversations[userIDStr] {
+		sendErrorMessage(c, "You already have an active conversation. Please wait for a response.")
+		return false
+	}
+	activeConversations[userIDStr] = true
+	return true
+}
+
+func endConversation(userIDStr string) {
+	conversationMutex.Lock()
+	delete(activeConversations, userIDStr)
+	conversationMutex.Unlock()
+}
+
 func AutomationWebSocketHandler(c *websocket.Conn, ctx context.Context, messageObj WebSocketMessage, userID primitive.ObjectID) {
 	userIDStr := userID.Hex()
 
 	switch messageObj.Type {
 	case MessageTypeAIRequest:
 		// Check if a conversation is already in progress for this user
-		conversationMutex.Lock()
-		if activeConversations[userIDStr] {
-			conversationMutex.Unlock()
-			sendErrorMessage(c, "You already have an active conversation. Please wait for a response.")
+		if !startConversation(c, userIDStr) {
 			return
 		}
 
-		// Mark this user as having an active conversation
-		activeConversations[userIDStr] = true
-		conversationMutex.Unlock()
-
 		// Process the request in a separate goroutine
 		go func() {
-			defer func() {
-				// When done, mark the conversation as inactive
-				conversationMutex.Lock()
-				delete(activeConversations, userIDStr)
-				conversationMutex.Unlock()
-			}()
-
+			// When done, mark the conversation as inactive
+			defer endConversation(userIDStr)
 			handleAIRequest(c, ctx, messageObj.Payload, userID)
 		}()
 
+	case MessageTypeAudioChunk:
+		audio, mimeType, complete := addAudioChunk(c, messageObj.Payload)
+		if !complete {
+			return
+		}
+		if !startConversation(c, userIDStr) {
+			return
+		}
+		go func() {
+			defer endConversation(userIDStr)
+			handleVoiceRequest(c, ctx, audio, mimeType, userID)
+		}()
+
 	case MessageTypeChat:
 		// Handle regular chat messages if needed
 		log.Printf("Chat message from user %s: %v", userIDStr, messageObj.Payload)
@@ -95,21 +152,111 @@ func handleAIRequest(c *websocket.Conn, ctx context.Context, payload interface{}
 	// Log the user's request to help with debugging
 	log.Printf("Processing AI request from user %s", userIDStr)
 
+	converse(c, ctx, request.Message, "", userID)
+}
+
+// addAudioChunk buffers one chunk of a dictated message. Once the final chunk
+// arrives it returns the whole recording and clears the buffer.
+func addAudioChunk(c *websocket.Conn, payload interface{}) ([]byte, string, bool) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		sendErrorMessage(c, "Invalid request format")
+		return nil, "", false
+	}
+	var chunk AudioChunk
+	if err := json.Unmarshal(payloadBytes, &chunk); err != nil {
+		sendErrorMessage(c, "Invalid request format")
+		return nil, "", false
+	}
+	data, err := base64.StdEncoding.DecodeString(chunk.Data)
+	if err != nil {
+		sendErrorMessage(c, "Audio data must be base64")
+		return nil, "", false
+	}
+
+	audioMutex.Lock()
+	defer audioMutex.Unlock()
+	buffer, ok := audioBuffers[c]
+	if !ok {
+		buffer = &audioBuffer{}
+		audioBuffers[c] = buffer
+	}
+	if chunk.MimeType != "" {
+		buffer.mimeType = chunk.MimeType
+	}
+	if len(buffer.data)+len(data) > speech.MaxAudioBytes {
+		delete(audioBuffers, c)
+		sendErrorMessage(c, fmt.Sprintf("The recording is larger than %d MB", speech.MaxAudioBytes>>20))
+		return nil, "", false
+	}
+	buffer.data = append(buffer.data, data...)
+	if !chunk.Final {
+		return nil, "", false
+	}
+	delete(audioBuffers, c)
+	return buffer.data, buffer.mimeType, true
+}
+
+// clearAudio drops a half-received recording when its connection closes
+func clearAudio(c *websocket.Conn) {
+	audioMutex.Lock()
+	delete(audioBuffers, c)
+	audioMutex.Unlock()
+}
+
+// handleVoiceRequest transcribes a dictated message, sends the transcript as
+// soon as it is known and then answers it like a typed message
+func handleVoiceRequest(c *websocket.Conn, ctx context.Context, audio []byte, mimeType string, userID primitive.ObjectID) {
+	log.Printf("Transcribing %d bytes of audio from user %s", len(audio), userID.Hex())
+	transcript, err := speech.Transcribe(aiconfig.WithScope(ctx, &userID, nil), audio, mimeType)
+	if err != nil {
+		sendAIError(c, err, "Transcription error")
+		return
+	}
+	sendMessage(c, WebSocketMessage{
+		Type:    MessageTypeTranscript,
+		Payload: TranscriptResponse{Transcript: transcript},
+	})
+	converse(c, ctx, transcript, transcript, userID)
+}
+
+// sendAIError reports a failed AI call, passing on quota and guardrail messages
+func sendAIError(c *websocket.Conn, err error, prefix string) {
+	var quota *usage.QuotaError
+	if errors.As(err, &quota) {
+		sendErrorMessage(c, quota.Message)
+		return
+	}
+	var refused *guard.Error
+	if errors.As(err, &refused) {
+		sendErrorMessage(c, refused.Message)
+		return
+	}
+	sendErrorMessage(c, fmt.Sprintf("%s: %v", prefix, err))
+}
+
+// converse sends a message to the assistant and the response back to the
+// client. transcript is set when the message was dictated.
+func converse(c *websocket.Conn, ctx context.Context, message string, transcript string, userID primitive.ObjectID) {
+	userIDStr := userID.Hex()
+
 	// Process the AI conversation, passing the userID as a string to identify the session
-	result, err := genai.ProcessConversation(ctx, request.Message, userIDStr)
+	result, err := genai.ProcessConversation(ctx, message, userIDStr)
 	if err != nil {
-		sendErrorMessage(c, fmt.Sprintf("AI processing error: %v", err))
+		sendAIError(c, err, "AI processing error")
 		return
 	}
 
 	// Log the AI response for debugging
-	log.Printf("AI response to user %s: %s", userIDStr, result)
+	log.Printf("AI response to user %s after %d tool calls: %s", userIDStr, len(result.Steps), result.Text)
 
 	// Send AI response
 	sendMessage(c, WebSocketMessage{
 		Type: MessageTypeAIResponse,
 		Payload: AIConversationResponse{
-			Message: result,
+			Message:    result.Text,
+			Transcript: transcript,
+			Steps:      result.Steps,
 		},
 	})
 }
@@ -71,3 +71,28 @@ func (w *WebSocketManager) Broadcast(message interface{}) {
 		}
 	}()
 }
+
+// SendToUser sends message to every open connection of a single user.
+// It reports whether the user had any connection at the time.
+func (w *WebSocketManager) SendToUser(userID primitive.ObjectID, message interface{}) bool {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Println("Failed to encode message:", err)
+		return false
+	}
+
+	w.mutex.Lock()
+	connections := append([]*Client{}, w.clients[userID]...)
+	w.mutex.Unlock()
+
+	for _, client := range connections {
+		go func(c *Client) {
+			if err := c.Conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				log.Println("WebSocket error:", err)
+				c.Conn.Close()
+				w.RemoveClient(c.Conn, c.UserID)
+			}
+		}(client)
+	}
+	return len(connections) > 0
+}
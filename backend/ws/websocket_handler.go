@@ -36,6 +36,7 @@ func HandleWebSocketConnection(c *websocket.Conn) {
 	WSManager.RegisterClient(c, userID)
 	defer func() {
 		WSManager.RemoveClient(c, userID)
+		clearAudio(c)
 		c.Close()
 	}()
 	var (
@@ -48,12 +49,17 @@ func HandleWebSocketConnection(c *websocket.Conn) {
 			log.Println("read error:", err)
 			break
 		}
-		log.Printf("received message: %s", msg)
 		if err := json.Unmarshal(msg, &messageObj); err != nil {
 			log.Printf("Failed to parse message: %v", err)
 			sendErrorMessage(c, "Invalid message format")
 			continue
 		}
+		if messageObj.Type == MessageTypeAudioChunk {
+			// Audio frames are large and the user's voice, so keep them out of the log
+			log.Printf("received %s message (%d bytes)", messageObj.Type, len(msg))
+		} else {
+			log.Printf("received message: %s", msg)
+		}
 		AutomationWebSocketHandler(c, ctx, messageObj, userID)
 	}
 }
@@ -3,6 +3,8 @@
 import { useState, useEffect } from "react";
 import { useParams, useRouter } from "next/navigation";
 import axios from "@/utils/AxiosInstance";
+import { isAxiosError } from "axios";
+import { toast } from "react-toastify";
 import {
   Card,
   CardContent,
@@ -41,6 +43,8 @@ export default function UpdateTaskForm() {
   const [users, setUsers] = useState<User[]>([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState(false);
+  // Version of the task being edited, sent back so concurrent edits are caught
+  const [version, setVersion] = useState(0);
   const [formData, setFormData] = useState({
     title: "",
     description: "",
@@ -69,6 +73,7 @@ export default function UpdateTaskForm() {
             priority: task.priority || PriorityType.LOW,
             assigned_to: task.assigned_to || [],
           });
+          setVersion(task.version || 0);
         } else {
           setError(true);
         }
@@ -108,9 +113,24 @@ export default function UpdateTaskForm() {
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
     try {
-      await axios.put(`/tasks/${taskId}`, formData);
+      await axios.put(`/tasks/${taskId}`, formData, {
+        headers: { "If-Match": `"${version}"` },
+      });
       router.push("/dashboard");
     } catch (error) {
+      if (isAxiosError(error) && error.response?.status === 412) {
+        const task = error.response.data.task;
+        setFormData({
+          title: task.title || "",
+          description: task.description || "",
+          status: task.status || StatusType.PENDING,
+          priority: task.priority || PriorityType.LOW,
+          assigned_to: task.assigned_to || [],
+        });
+        setVersion(task.version || 0);
+        toast.error("Someone else changed this task. Review their changes and save again.");
+        return;
+      }
       console.error("Error updating task:", error);
     }
   };